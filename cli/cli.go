@@ -1,15 +1,21 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 )
 
 type GenericResponse struct {
@@ -19,8 +25,11 @@ type GenericResponse struct {
 	Data         any `json:"data"`
 }
 
-func printJSON(w io.Writer, data any) error {
-	b, err := json.MarshalIndent(&data, "", "  ")
+// defaultJSONIndent is the indent used for json output unless overridden.
+const defaultJSONIndent = "  "
+
+func printJSON(w io.Writer, data any, indent string) error {
+	b, err := json.MarshalIndent(&data, "", indent)
 	if err != nil {
 		return fmt.Errorf("unable to convert data to bytes: %w", err)
 	}
@@ -28,17 +37,767 @@ func printJSON(w io.Writer, data any) error {
 	return nil
 }
 
+// printJSONQuotedIDs is printJSON with ids (id, show_id, song_ids, etc.)
+// written as strings, for consumers that mangle big integers.
+func printJSONQuotedIDs(w io.Writer, data any, indent string) error {
+	b, err := json.Marshal(&data)
+	if err != nil {
+		return fmt.Errorf("unable to convert data to bytes: %w", err)
+	}
+	if b, err = quoteIDs(b); err != nil {
+		return fmt.Errorf("unable to convert data to bytes: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", indent); err != nil {
+		return fmt.Errorf("unable to convert data to bytes: %w", err)
+	}
+	fmt.Fprintln(w, buf.String())
+	return nil
+}
+
+// isIDField reports whether numbers under key are ids, either one (id,
+// show_id) or, in an array, several (song_ids).
+func isIDField(key string, inArray bool) bool {
+	if inArray {
+		return strings.HasSuffix(key, "_ids")
+	}
+	return key == "id" || strings.HasSuffix(key, "_id")
+}
+
+// quoteIDs rewrites the id numbers in the json b as strings. It walks the
+// tokens rather than decoding into maps so the fields keep their order.
+func quoteIDs(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	type frame struct {
+		object bool
+		// wantKey is set when an object's next token is a key
+		wantKey bool
+		n       int
+		// key is the object key being read, or the key an array is under
+		key string
+	}
+	var out bytes.Buffer
+	var stack []*frame
+	// separate writes a comma ahead of a container's second and later
+	// entries
+	separate := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.n > 0 && (!top.object || top.wantKey) {
+			out.WriteByte(',')
+		}
+	}
+	// done moves the container past the value just written
+	done := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		top.n++
+		top.wantKey = top.object
+	}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{', '[':
+				separate()
+				out.WriteRune(rune(v))
+				f := &frame{object: v == '{', wantKey: v == '{'}
+				if top != nil {
+					f.key = top.key
+				}
+				stack = append(stack, f)
+				continue
+			default:
+				out.WriteRune(rune(v))
+				stack = stack[:len(stack)-1]
+				done()
+				continue
+			}
+		case string:
+			if top != nil && top.object && top.wantKey {
+				separate()
+				k, _ := json.Marshal(v)
+				out.Write(k)
+				out.WriteByte(':')
+				top.key = v
+				top.wantKey = false
+				continue
+			}
+		}
+		separate()
+		if n, ok := tok.(json.Number); ok && top != nil && isIDField(top.key, !top.object) {
+			tok = n.String()
+		}
+		v, err := json.Marshal(tok)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(v)
+		done()
+	}
+}
+
 type PrettyPrinter interface {
 	PrettyPrint(io.Writer, bool) error
 }
 
+// Pagination holds the paging metadata returned alongside list responses.
+type Pagination struct {
+	TotalEntries int `json:"total_entries"`
+	TotalPages   int `json:"total_pages"`
+	CurrentPage  int `json:"current_page"`
+}
+
+// newPagination returns nil when there's no page information, which is the
+// case for detail responses that reuse a list output (e.g. year details).
+func newPagination(totalEntries, totalPages, currentPage int) *Pagination {
+	if currentPage == 0 {
+		return nil
+	}
+	return &Pagination{
+		TotalEntries: totalEntries,
+		TotalPages:   totalPages,
+		CurrentPage:  currentPage,
+	}
+}
+
+// JSONEnvelope is the shape of all json output. List endpoints include
+// pagination, detail endpoints omit it.
+type JSONEnvelope struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Data       any         `json:"data"`
+}
+
+// enveloper is implemented by outputs that need more than the default
+// {"data": ...} envelope, i.e. the list outputs.
+type enveloper interface {
+	envelope() JSONEnvelope
+}
+
+// summarizer is implemented by the list outputs, which can print a one-line
+// summary (--summary) ahead of their table.
+type summarizer interface {
+	summary() string
+}
+
+// summarize describes a page of results, e.g. "20 shows (page 1 of 88, 1760 total)".
+func summarize(n int, noun string, p *Pagination) string {
+	if n != 1 {
+		noun += "s"
+	}
+	if p == nil {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %s (page %d of %d, %d total)", n, noun, p.CurrentPage, p.TotalPages, p.TotalEntries)
+}
+
+func newJSONEnvelope(pp PrettyPrinter) JSONEnvelope {
+	if e, ok := pp.(enveloper); ok {
+		return e.envelope()
+	}
+	return JSONEnvelope{Data: pp}
+}
+
 func PrintResults(w io.Writer, pp PrettyPrinter, json, verbose bool) error {
 	if json {
-		return printJSON(w, pp)
+		return printJSON(w, newJSONEnvelope(pp), defaultJSONIndent)
 	}
 	return pp.PrettyPrint(w, verbose)
 }
 
+// tabular is implemented by the list outputs -o tsv can print.
+type tabular interface {
+	tsv() (header []string, rows [][]string)
+}
+
+// tsvCell keeps a cell on its line and in its column, swapping any tab or
+// newline in it for a space.
+var tsvCell = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// printTSV writes t as tab-separated rows under a header. Unlike csv,
+// nothing is quoted, so a location like "Boulder, CO" reads as is.
+func printTSV(w io.Writer, t tabular) error {
+	header, rows := t.tsv()
+	for _, row := range append([][]string{header}, rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = tsvCell.Replace(cell)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTabWriter returns the tabwriter used by the printers. tabwriter pads
+// every tab-terminated cell, so a line whose last cells are empty would end
+// in spaces; those are trimmed before reaching w.
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(&trimWriter{w: w}, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+}
+
+// trimWriter drops spaces at the end of each line. Spaces are held back
+// until something other than a newline follows them.
+type trimWriter struct {
+	w       io.Writer
+	pending int
+}
+
+func (t *trimWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch b {
+		case ' ':
+			t.pending++
+			continue
+		case '\n':
+			t.pending = 0
+		default:
+			out = append(out, bytes.Repeat([]byte{' '}, t.pending)...)
+			t.pending = 0
+		}
+		out = append(out, b)
+	}
+	if _, err := t.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// asciiFolds lists the non-ASCII characters --ascii replaces, keyed by their
+// replacement. It covers the Latin-1 and Latin Extended-A letters found in
+// venue names and locations (e.g. Montréal, Kraków), plus typographic
+// punctuation.
+var asciiFolds = map[string]string{
+	"A":   "ÀÁÂÃÄÅĀĂĄ",
+	"a":   "àáâãäåāăą",
+	"AE":  "Æ",
+	"ae":  "æ",
+	"C":   "ÇĆĈĊČ",
+	"c":   "çćĉċč",
+	"D":   "ÐĎĐ",
+	"d":   "ðďđ",
+	"E":   "ÈÉÊËĒĔĖĘĚ",
+	"e":   "èéêëēĕėęě",
+	"G":   "ĜĞĠĢ",
+	"g":   "ĝğġģ",
+	"H":   "ĤĦ",
+	"h":   "ĥħ",
+	"I":   "ÌÍÎÏĨĪĬĮİ",
+	"i":   "ìíîïĩīĭįı",
+	"IJ":  "Ĳ",
+	"ij":  "ĳ",
+	"J":   "Ĵ",
+	"j":   "ĵ",
+	"K":   "Ķ",
+	"k":   "ķĸ",
+	"L":   "ĹĻĽĿŁ",
+	"l":   "ĺļľŀł",
+	"N":   "ÑŃŅŇŊ",
+	"n":   "ñńņňŋ",
+	"O":   "ÒÓÔÕÖØŌŎŐ",
+	"o":   "òóôõöøōŏő",
+	"OE":  "Œ",
+	"oe":  "œ",
+	"R":   "ŔŖŘ",
+	"r":   "ŕŗř",
+	"S":   "ŚŜŞŠ",
+	"s":   "śŝşšſ",
+	"ss":  "ß",
+	"T":   "ŢŤŦ",
+	"t":   "ţťŧ",
+	"Th":  "Þ",
+	"th":  "þ",
+	"U":   "ÙÚÛÜŨŪŬŮŰŲ",
+	"u":   "ùúûüũūŭůűų",
+	"W":   "Ŵ",
+	"w":   "ŵ",
+	"Y":   "ÝŶŸ",
+	"y":   "ýÿŷ",
+	"Z":   "ŹŻŽ",
+	"z":   "źżž",
+	"'":   "‘’′",
+	"\"":  "“”″",
+	"-":   "‐–—",
+	"...": "…",
+	" ":   "\u00a0",
+}
+
+// asciiRunes maps each character in asciiFolds to its replacement.
+var asciiRunes = func() map[rune]string {
+	m := make(map[rune]string)
+	for ascii, runes := range asciiFolds {
+		for _, r := range runes {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+// asciiWriter transliterates output to ASCII (--ascii), e.g. é to e, with a
+// ? for anything it doesn't know. It sits after the tabwriter, so a letter
+// that becomes two (ß to ss) pushes the rest of its row over by one.
+type asciiWriter struct {
+	w io.Writer
+	// partial is the start of a character split across writes
+	partial []byte
+}
+
+func (a *asciiWriter) Write(p []byte) (int, error) {
+	b := append(a.partial, p...)
+	a.partial = nil
+	out := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		if b[0] < utf8.RuneSelf {
+			out = append(out, b[0])
+			b = b[1:]
+			continue
+		}
+		if !utf8.FullRune(b) {
+			a.partial = append([]byte(nil), b...)
+			break
+		}
+		r, size := utf8.DecodeRune(b)
+		if ascii, ok := asciiRunes[r]; ok {
+			out = append(out, ascii...)
+		} else {
+			out = append(out, '?')
+		}
+		b = b[size:]
+	}
+	if _, err := a.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// highlightWriter marks each case-insensitive match of a term in text
+// output (--highlight). Output is transformed a line at a time, so call
+// Flush to write out any last line without a newline.
+type highlightWriter struct {
+	w           io.Writer
+	re          *regexp.Regexp
+	replacement []byte
+	// line is the start of a line split across writes
+	line []byte
+}
+
+// newHighlightWriter marks matches of term in reverse video when color is
+// on, or with ** otherwise, e.g. **Bowie**. Only reverse video is turned off
+// after a match, so it keeps any theme color around it.
+func newHighlightWriter(w io.Writer, term string, color bool) *highlightWriter {
+	start, end := "**", "**"
+	if color {
+		start, end = ansiReverse, ansiReverseOff
+	}
+	return &highlightWriter{
+		w:           w,
+		re:          regexp.MustCompile("(?i)" + regexp.QuoteMeta(term)),
+		replacement: []byte(start + "$0" + end),
+	}
+}
+
+func (h *highlightWriter) Write(p []byte) (int, error) {
+	h.line = append(h.line, p...)
+	i := bytes.LastIndexByte(h.line, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	if _, err := h.w.Write(h.highlight(h.line[:i+1])); err != nil {
+		return 0, err
+	}
+	h.line = append(h.line[:0], h.line[i+1:]...)
+	return len(p), nil
+}
+
+func (h *highlightWriter) Flush() error {
+	if len(h.line) == 0 {
+		return nil
+	}
+	_, err := h.w.Write(h.highlight(h.line))
+	h.line = h.line[:0]
+	return err
+}
+
+// ansiEscape matches the color codes a theme puts in text output.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// highlight marks the matches in text, leaving the theme's color codes
+// alone so a term like "m" or "3" can't match inside one.
+func (h *highlightWriter) highlight(text []byte) []byte {
+	var out []byte
+	prev := 0
+	for _, loc := range ansiEscape.FindAllIndex(text, -1) {
+		out = append(out, h.re.ReplaceAll(text[prev:loc[0]], h.replacement)...)
+		out = append(out, text[loc[0]:loc[1]]...)
+		prev = loc[1]
+	}
+	return append(out, h.re.ReplaceAll(text[prev:], h.replacement)...)
+}
+
+// ansi escape codes used by the themes
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiReverse = "\x1b[7m"
+	// ansiReverseOff ends reverse video without resetting the color
+	ansiReverseOff = "\x1b[27m"
+)
+
+// theme holds the color used to highlight each kind of key data in text
+// output. A nil theme leaves text uncolored.
+type theme struct {
+	Date     string
+	Venue    string
+	Duration string
+}
+
+const defaultTheme = "default"
+
+var themes = map[string]theme{
+	defaultTheme: {Date: ansiYellow, Venue: ansiCyan, Duration: ansiGreen},
+	"ocean":      {Date: ansiBlue, Venue: ansiCyan, Duration: ansiMagenta},
+	"sunset":     {Date: ansiRed, Venue: ansiMagenta, Duration: ansiYellow},
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func paint(code, s string) string {
+	return code + s + ansiReset
+}
+
+func (t *theme) date(s string) string {
+	if t == nil {
+		return s
+	}
+	return paint(t.Date, s)
+}
+
+func (t *theme) venue(s string) string {
+	if t == nil {
+		return s
+	}
+	return paint(t.Venue, s)
+}
+
+func (t *theme) duration(s string) string {
+	if t == nil {
+		return s
+	}
+	return paint(t.Duration, s)
+}
+
+// tintCell colors s with a hex color (e.g. #888888) as a 24-bit foreground.
+// The escape codes are zero-padded to the same length whatever the color
+// (including none, for headers and invalid colors), so every cell in a
+// column keeps the same invisible width and the table stays aligned.
+func tintCell(hex string, s string) string {
+	code := "\x1b[0000000000000039m" // default foreground
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err == nil && len(hex) == 7 {
+		code = fmt.Sprintf("\x1b[38;2;%03d;%03d;%03dm", r, g, b)
+	}
+	return code + s + ansiReset
+}
+
+// printOptions are text output settings only some printers support.
+type printOptions struct {
+	// theme highlights key data. Cells in a column need to be colored alike
+	// (headers included) to stay aligned.
+	theme *theme
+	// links adds the phish.in page for each show
+	links bool
+	// columns orders (and picks) the verbose shows table columns
+	columns []string
+	// segues joins segued tracks in a show's setlist
+	segues bool
+	// jsonl prints search results as one json object per line
+	jsonl bool
+	// seconds prints show and track durations as whole seconds
+	seconds bool
+	// clock prints show and track durations as H:MM:SS, which line up in a
+	// column whether or not they run past an hour
+	clock bool
+	// mergeSets lists a show's tracks as one list, without set headers
+	mergeSets bool
+	// includeEmpty prints a section's header even when it has no data
+	includeEmpty bool
+	// maxTracks caps the tracks listed for a show or song (0 lists them all)
+	maxTracks int
+	// context trims search track tag notes and transcripts to this many
+	// characters either side of the search term
+	context int
+}
+
+// capTracks returns the first maxTracks tracks, along with a note saying so
+// when that's fewer than all of them.
+func (o printOptions) capTracks(tracks []TrackOutput) ([]TrackOutput, string) {
+	if o.maxTracks <= 0 || len(tracks) <= o.maxTracks {
+		return tracks, ""
+	}
+	return tracks[:o.maxTracks], fmt.Sprintf("(showing %d of %d tracks)", o.maxTracks, len(tracks))
+}
+
+// printNone prints header followed by (none), for an empty section that
+// --include-empty keeps.
+func (o printOptions) printNone(w io.Writer, header string) {
+	if !o.includeEmpty {
+		return
+	}
+	fmt.Fprintf(w, "%s (none)\n", header)
+	fmt.Fprintln(w)
+}
+
+// formatDuration returns the duration to print for ms milliseconds, where
+// human is its usual form (e.g. 13m 31s).
+func (o printOptions) formatDuration(human string, ms int64) string {
+	switch {
+	case o.seconds:
+		return strconv.FormatInt(ms/1000, 10)
+	case o.clock:
+		secs := ms / 1000
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return human
+}
+
+// reformatsDurations reports whether durations print in a form other than
+// the human one.
+func (o printOptions) reformatsDurations() bool {
+	return o.seconds || o.clock
+}
+
+// withDurations returns a copy of show with its (and its tracks') durations
+// formatted per o.
+func (o printOptions) withDurations(show ShowOutput) ShowOutput {
+	if !o.reformatsDurations() {
+		return show
+	}
+	show.Duration = o.formatDuration(show.Duration, show.durationMS)
+	tracks := make([]TrackOutput, len(show.Tracks))
+	for i, t := range show.Tracks {
+		t.Duration = o.formatDuration(t.Duration, t.durationMS)
+		tracks[i] = t
+	}
+	show.Tracks = tracks
+	return show
+}
+
+// setlist returns the tracks to list in a show's setlist. With segues on,
+// a run of segued tracks becomes one entry, e.g. "Mike's Song > I Am
+// Hydrogen > Weekapaug Groove", with the durations joined the same way.
+func (o printOptions) setlist(tracks []TrackOutput) []TrackOutput {
+	if !o.segues {
+		return tracks
+	}
+	merged := make([]TrackOutput, 0, len(tracks))
+	joining := false
+	for _, t := range tracks {
+		last := len(merged) - 1
+		// segues don't cross set breaks
+		if joining && merged[last].SetName == t.SetName {
+			merged[last].Title += " > " + t.Title
+			merged[last].Duration += " > " + t.Duration
+		} else {
+			merged = append(merged, t)
+		}
+		joining = segues(t)
+	}
+	return merged
+}
+
+// segues reports whether a track segues into the next one. phish.in has no
+// segue field, so this approximates from the tags: a tag named "Segue", or
+// tag notes that mention a segue (or use "->" or ">" the way setlists do).
+func segues(t TrackOutput) bool {
+	for _, tag := range t.Tags {
+		if strings.EqualFold(tag.Name, "segue") {
+			return true
+		}
+		notes := strings.ToLower(tag.Notes)
+		if strings.Contains(notes, "segue") || strings.Contains(notes, "->") || strings.HasPrefix(strings.TrimSpace(notes), ">") {
+			return true
+		}
+	}
+	return false
+}
+
+// SetlistNotationOutput is a show's setlist the way fans share it, one
+// line per set (--setlist-notation).
+type SetlistNotationOutput struct {
+	Date string       `json:"date"`
+	Sets []SetlistSet `json:"sets"`
+}
+
+// SetlistSet is one set of a SetlistNotationOutput, e.g. "Possum, Ya Mar >
+// Bowie".
+type SetlistSet struct {
+	Name    string `json:"name"`
+	Setlist string `json:"setlist"`
+}
+
+// setlistNotation joins each set's tracks with ", ", or " > " after a track
+// that segues (going by segues).
+func setlistNotation(show ShowOutput) SetlistNotationOutput {
+	o := SetlistNotationOutput{Date: show.Date, Sets: []SetlistSet{}}
+	var b strings.Builder
+	for i, t := range show.Tracks {
+		if i == 0 || t.SetName != show.Tracks[i-1].SetName {
+			if i > 0 {
+				o.Sets = append(o.Sets, SetlistSet{Name: show.Tracks[i-1].SetName, Setlist: b.String()})
+				b.Reset()
+			}
+		} else if segues(show.Tracks[i-1]) {
+			b.WriteString(" > ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.Title)
+	}
+	if len(show.Tracks) > 0 {
+		o.Sets = append(o.Sets, SetlistSet{Name: show.Tracks[len(show.Tracks)-1].SetName, Setlist: b.String()})
+	}
+	return o
+}
+
+func (s SetlistNotationOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	for _, set := range s.Sets {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", set.Name, set.Setlist); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// showColumn is a column of the verbose shows table.
+type showColumn struct {
+	header string
+	value  func(ShowOutput) string
+	// color is the theme's color for the column, nil if it isn't highlighted
+	color func(*theme, string) string
+}
+
+func (c showColumn) paint(t *theme, s string) string {
+	if c.color == nil {
+		return s
+	}
+	return c.color(t, s)
+}
+
+var showColumns = map[string]showColumn{
+	"id":         {header: "ID:", value: func(s ShowOutput) string { return strconv.Itoa(s.ID) }},
+	"date":       {header: "Date:", value: func(s ShowOutput) string { return s.Date }, color: (*theme).date},
+	"venue":      {header: "Venue:", value: func(s ShowOutput) string { return s.VenueName }, color: (*theme).venue},
+	"location":   {header: "Location:", value: func(s ShowOutput) string { return s.VenueLocation }},
+	"duration":   {header: "Duration:", value: func(s ShowOutput) string { return s.Duration }, color: (*theme).duration},
+	"sbd":        {header: "Soundboard:", value: func(s ShowOutput) string { return trueAsYes(s.Sbd).String() }},
+	"remastered": {header: "Remastered:", value: func(s ShowOutput) string { return trueAsYes(s.Remastered).String() }},
+	"link":       {header: "Link:", value: func(s ShowOutput) string { return showLink(s.Date) }},
+}
+
+var defaultShowColumns = []string{"id", "date", "venue", "location", "duration", "sbd", "remastered"}
+
+// parseColumns validates a comma-separated list of verbose show columns.
+func parseColumns(spec string) ([]string, error) {
+	var cols []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := showColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q (options are %s, link)", name, strings.Join(defaultShowColumns, ", "))
+		}
+		cols = append(cols, name)
+	}
+	return cols, nil
+}
+
+// verboseShowColumns returns the columns to print, in order. --links adds
+// the link column unless it's already included.
+func (o printOptions) verboseShowColumns() []showColumn {
+	names := o.columns
+	if len(names) == 0 {
+		names = defaultShowColumns
+	}
+	hasLink := false
+	cols := make([]showColumn, 0, len(names)+1)
+	for _, name := range names {
+		hasLink = hasLink || name == "link"
+		cols = append(cols, showColumns[name])
+	}
+	if o.links && !hasLink {
+		cols = append(cols, showColumns["link"])
+	}
+	return cols
+}
+
+// configurable printers honor printOptions.
+type configurable interface {
+	withOptions(printOptions) PrettyPrinter
+}
+
+// withLinkColumn adds cell as a final column of line when links are on.
+func (o printOptions) withLinkColumn(line, cell string) string {
+	if !o.links {
+		return line
+	}
+	return line + "\t" + cell
+}
+
+// siteURL is the phish.in website, which shares its paths (songs/tweezer)
+// with the api.
+const siteURL = "https://phish.in"
+
+// showLink is the phish.in page for the show on date, or "" if date isn't
+// a valid yyyy-mm-dd.
+func showLink(date string) string {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return ""
+	}
+	return siteURL + "/" + date
+}
+
+// isTerminal reports whether w is a terminal, in which case color is on by
+// default.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 type trueAsYes bool
 
 func (s trueAsYes) String() string {
@@ -81,6 +840,8 @@ type Show struct {
 	Location   string    `json:"location"`
 	Tracks     []Track   `json:"tracks"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// CoverArt is the show's album art, when the api has any
+	CoverArt string `json:"album_cover_url"`
 }
 
 // Song is a convenience struct to hold the song data in the API response.
@@ -98,10 +859,17 @@ type Song struct {
 }
 
 // Tag is a convenience struct to hold the tag data in the API response.
+// A track's tags can also mark where in it the tag applies.
 type Tag struct {
-	Name  string `json:"name"`
-	Group string `json:"group"`
-	Notes string `json:"notes"`
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Priority       int    `json:"priority"`
+	Group          string `json:"group"`
+	Color          string `json:"color"`
+	Notes          string `json:"notes"`
+	Transcript     string `json:"transcript,omitempty"`
+	StartsAtSecond int    `json:"starts_at_second,omitempty"`
+	EndsAtSecond   int    `json:"ends_at_second,omitempty"`
 }
 
 // TagListItem is a convenience struct to hold the tag data in the API response
@@ -194,28 +962,99 @@ type Year struct {
 /////////
 
 type ErasResponse struct {
-	Data struct {
-		One   []string `json:"1.0"`
-		Two   []string `json:"2.0"`
-		Three []string `json:"3.0"`
-		Four  []string `json:"4.0"`
-	} `json:"data"`
+	Data map[string][]string `json:"data"`
 }
 
-type ErasOutput struct {
-	One   []string `json:"1.0"`
-	Two   []string `json:"2.0"`
-	Three []string `json:"3.0"`
-	Four  []string `json:"4.0"`
+// knownEras are always listed, even if /eras leaves one out.
+var knownEras = []string{"1.0", "2.0", "3.0", "4.0"}
+
+// ErasOutput maps each era (e.g. 3.0) to its years. Eras past the known
+// ones (a 5.0, say) are kept too.
+type ErasOutput map[string][]string
+
+// names lists the eras in order, e.g. 1.0, 2.0, ..., 10.0.
+func (e ErasOutput) names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.ParseFloat(names[i], 64)
+		b, errB := strconv.ParseFloat(names[j], 64)
+		if errA != nil || errB != nil || a == b {
+			return names[i] < names[j]
+		}
+		return a < b
+	})
+	return names
 }
 
 func (e ErasOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	_, err := fmt.Fprintf(w,
-		"Eras\n1.0: %v\n2.0: %v\n3.0: %v\n4.0: %v\n", strings.Join(e.One, ", "), strings.Join(e.Two, ", "), strings.Join(e.Three, ", "), strings.Join(e.Four, ", "),
-	)
+	var sb strings.Builder
+	sb.WriteString("Eras\n")
+	for _, name := range e.names() {
+		fmt.Fprintf(&sb, "%s: %s\n", name, strings.Join(e[name], ", "))
+	}
+	_, err := io.WriteString(w, sb.String())
 	return err
 }
 
+// EraCountsOutput is each era's show count, in era order (eras --counts).
+type EraCountsOutput []EraCount
+
+type EraCount struct {
+	Era       string   `json:"era"`
+	Years     []string `json:"years"`
+	ShowCount int      `json:"show_count"`
+}
+
+// countEraShows totals the show counts of each era's years. phish.in lists
+// 1983-1987 as one year, which is summed from its years when the years
+// list has them on their own (--normalize-dates).
+func countEraShows(eras ErasOutput, years []Year) EraCountsOutput {
+	counts := make(map[string]int, len(years))
+	for _, y := range years {
+		counts[y.Date] = y.ShowCount
+	}
+	o := make(EraCountsOutput, 0, len(eras))
+	for _, name := range eras.names() {
+		era := EraCount{Era: name, Years: eras[name]}
+		for _, year := range eras[name] {
+			if n, ok := counts[year]; ok {
+				era.ShowCount += n
+				continue
+			}
+			span, err := expandYears([]string{year})
+			if err != nil {
+				continue
+			}
+			for _, y := range span {
+				era.ShowCount += counts[y]
+			}
+		}
+		o = append(o, era)
+	}
+	return o
+}
+
+func (e EraCountsOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "Era:\tYears:\tShows:")
+	for _, era := range e {
+		// e.g. 1983-2000 for 1.0
+		var years string
+		if len(era.Years) > 0 {
+			first, _, errFirst := parseYearBucket(era.Years[0])
+			_, last, errLast := parseYearBucket(era.Years[len(era.Years)-1])
+			if errFirst == nil && errLast == nil {
+				years = fmt.Sprintf("%d-%d", first, last)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", era.Era, years, era.ShowCount)
+	}
+	return tw.Flush()
+}
+
 type EraResponse struct {
 	Era []string `json:"data"`
 }
@@ -242,15 +1081,42 @@ type YearsResponse struct {
 }
 
 type YearsOutput struct {
-	Years []Year `json:"years"`
+	TotalEntries int    `json:"total_entries"`
+	TotalPages   int    `json:"total_pages"`
+	CurrentPage  int    `json:"current_page"`
+	Years        []Year `json:"years"`
+	// ShowCount sums the listed years' shows when --from/--to narrow the list.
+	ShowCount int `json:"show_count,omitempty"`
+}
+
+func (y YearsOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(y.TotalEntries, y.TotalPages, y.CurrentPage),
+		Data:       y.Years,
+	}
+}
+
+func (y YearsOutput) summary() string {
+	return summarize(len(y.Years), "year", newPagination(y.TotalEntries, y.TotalPages, y.CurrentPage))
+}
+
+func (y YearsOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(y.Years))
+	for _, year := range y.Years {
+		rows = append(rows, []string{year.Date, strconv.Itoa(year.ShowCount)})
+	}
+	return []string{"Year", "Show Count"}, rows
 }
 
 func (y YearsOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Years:\tShow Count:")
 	for _, year := range y.Years {
 		fmt.Fprintf(tw, "%s\t%d\n", year.Date, year.ShowCount)
 	}
+	if y.ShowCount != 0 {
+		fmt.Fprintf(tw, "Total:\t%d\n", y.ShowCount)
+	}
 	return tw.Flush()
 }
 
@@ -280,8 +1146,31 @@ type SongsOutput struct {
 	Songs        []SongOutput `json:"songs"`
 }
 
+func (s SongsOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(s.TotalEntries, s.TotalPages, s.CurrentPage),
+		Data:       s.Songs,
+	}
+}
+
+func (s SongsOutput) summary() string {
+	return summarize(len(s.Songs), "song", newPagination(s.TotalEntries, s.TotalPages, s.CurrentPage))
+}
+
+func (s SongsOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(s.Songs))
+	for _, song := range s.Songs {
+		artist := "Phish"
+		if !song.Original {
+			artist = song.Artist
+		}
+		rows = append(rows, []string{strconv.Itoa(song.ID), song.Title, artist, strconv.Itoa(song.TracksCount)})
+	}
+	return []string{"ID", "Title", "Original Artist", "Tracks Count"}, rows
+}
+
 func (s SongsOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Title:\tOriginal Artist:\tTracksCount:")
 	for _, song := range s.Songs {
 		artist := "Phish"
@@ -297,6 +1186,171 @@ func (s SongsOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	return tw.Flush()
 }
 
+// groupSongsByArtist groups songs under their original artist, with Phish
+// originals grouped under "Phish". Artists with the most songs come first.
+func groupSongsByArtist(songs []SongOutput) SongsByArtistOutput {
+	byArtist := make(map[string]*ArtistSongs)
+	var artists []*ArtistSongs
+	for _, song := range songs {
+		artist := "Phish"
+		if !song.Original {
+			artist = song.Artist
+		}
+		a, ok := byArtist[artist]
+		if !ok {
+			a = &ArtistSongs{Artist: artist}
+			byArtist[artist] = a
+			artists = append(artists, a)
+		}
+		a.Count++
+		a.Songs = append(a.Songs, song.Title)
+	}
+	sort.SliceStable(artists, func(i, j int) bool {
+		if artists[i].Count != artists[j].Count {
+			return artists[i].Count > artists[j].Count
+		}
+		return artists[i].Artist < artists[j].Artist
+	})
+	o := SongsByArtistOutput{Artists: make([]ArtistSongs, 0, len(artists))}
+	for _, a := range artists {
+		o.Artists = append(o.Artists, *a)
+	}
+	return o
+}
+
+// ArtistSongs is a group of songs by the same original artist.
+type ArtistSongs struct {
+	Artist string   `json:"artist"`
+	Count  int      `json:"count"`
+	Songs  []string `json:"songs"`
+}
+
+type SongsByArtistOutput struct {
+	Artists []ArtistSongs `json:"artists"`
+}
+
+func (s SongsByArtistOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	if verbose {
+		fmt.Fprintln(tw, "Original Artist:\tCount:\tSongs:")
+		for _, a := range s.Artists {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", a.Artist, a.Count, strings.Join(a.Songs, ", "))
+		}
+		return tw.Flush()
+	}
+	fmt.Fprintln(tw, "Original Artist:\tCount:")
+	for _, a := range s.Artists {
+		fmt.Fprintf(tw, "%s\t%d\n", a.Artist, a.Count)
+	}
+	return tw.Flush()
+}
+
+// groupShowsByVenue groups shows under their venue, keeping each venue's
+// shows in list order. Venues with the most shows come first.
+func groupShowsByVenue(shows []ShowOutput) ShowsByVenueOutput {
+	byVenue := make(map[string]*VenueShows)
+	var venues []*VenueShows
+	for _, show := range shows {
+		// venue names aren't unique (e.g. a Civic Center), so key by location too
+		key := show.VenueName + "\x00" + show.VenueLocation
+		v, ok := byVenue[key]
+		if !ok {
+			v = &VenueShows{Venue: show.VenueName, Location: show.VenueLocation}
+			byVenue[key] = v
+			venues = append(venues, v)
+		}
+		v.Count++
+		v.Dates = append(v.Dates, show.Date)
+	}
+	sort.SliceStable(venues, func(i, j int) bool {
+		return venues[i].Count > venues[j].Count
+	})
+	o := ShowsByVenueOutput{Venues: make([]VenueShows, 0, len(venues))}
+	for _, v := range venues {
+		o.Venues = append(o.Venues, *v)
+	}
+	return o
+}
+
+// VenueShows is a group of shows at the same venue.
+type VenueShows struct {
+	Venue    string   `json:"venue"`
+	Location string   `json:"location"`
+	Count    int      `json:"count"`
+	Dates    []string `json:"dates"`
+}
+
+type ShowsByVenueOutput struct {
+	Venues []VenueShows `json:"venues"`
+}
+
+func (s ShowsByVenueOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	for i, v := range s.Venues {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		noun := "shows"
+		if v.Count == 1 {
+			noun = "show"
+		}
+		fmt.Fprintf(tw, "%s, %s (%d %s)\n", v.Venue, v.Location, v.Count, noun)
+		for _, d := range v.Dates {
+			fmt.Fprintln(tw, d)
+		}
+	}
+	return tw.Flush()
+}
+
+// topCoveredArtists is how many artists songs --breakdown lists.
+const topCoveredArtists = 10
+
+// breakDownSongs counts songs as Phish originals or covers, listing the
+// artists with the most covers.
+func breakDownSongs(songs []SongOutput) SongsBreakdownOutput {
+	o := SongsBreakdownOutput{TopCoveredArtists: []ArtistCount{}}
+	for _, a := range groupSongsByArtist(songs).Artists {
+		if a.Artist == "Phish" {
+			o.Originals = a.Count
+			continue
+		}
+		o.Covers += a.Count
+		if len(o.TopCoveredArtists) < topCoveredArtists {
+			o.TopCoveredArtists = append(o.TopCoveredArtists, ArtistCount{Artist: a.Artist, Count: a.Count})
+		}
+	}
+	return o
+}
+
+// ArtistCount is how many songs of an artist's Phish has covered.
+type ArtistCount struct {
+	Artist string `json:"artist"`
+	Count  int    `json:"count"`
+}
+
+// SongsBreakdownOutput is the originals and covers among songs (songs
+// --breakdown).
+type SongsBreakdownOutput struct {
+	Originals         int           `json:"originals"`
+	Covers            int           `json:"covers"`
+	TopCoveredArtists []ArtistCount `json:"top_covered_artists"`
+}
+
+func (s SongsBreakdownOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "Originals:\tCovers:")
+	fmt.Fprintf(tw, "%d\t%d\n", s.Originals, s.Covers)
+	if len(s.TopCoveredArtists) == 0 {
+		return tw.Flush()
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Most Covered Artists:\tCount:")
+	for _, a := range s.TopCoveredArtists {
+		fmt.Fprintf(tw, "%s\t%d\n", a.Artist, a.Count)
+	}
+	return tw.Flush()
+}
+
 type SongResponse struct {
 	Data Song `json:"data"`
 }
@@ -321,10 +1375,39 @@ type SongOutput struct {
 	Artist      string        `json:"artist"`
 	TracksCount int           `json:"tracks_count"`
 	Tracks      []TrackOutput `json:"tracks"`
+	// TracksPage is set when the tracks are a page of the song's tracks.
+	TracksPage *Pagination `json:"tracks_page,omitempty"`
+	opts       printOptions
+}
+
+func (s SongOutput) withOptions(o printOptions) PrettyPrinter {
+	s.opts = o
+	return s
+}
+
+// pageTracks keeps the page'th (from 1) perPage tracks of the song, with
+// 1 and 20 standing in for a page or perPage of 0.
+func (s SongOutput) pageTracks(page, perPage int) (SongOutput, error) {
+	if page == 0 {
+		page = 1
+	}
+	if perPage == 0 {
+		perPage = 20
+	}
+	total := len(s.Tracks)
+	pages := (total + perPage - 1) / perPage
+	if page > pages && total != 0 {
+		return SongOutput{}, fmt.Errorf("track page %d is past the last page (%d)", page, pages)
+	}
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+	s.Tracks = s.Tracks[start:end]
+	s.TracksPage = &Pagination{TotalEntries: total, TotalPages: pages, CurrentPage: page}
+	return s, nil
 }
 
 func (s SongOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Title:\tID:\tOriginal Artist:\tTracksCount:")
 	artist := "Phish"
 	if !s.Original {
@@ -334,14 +1417,26 @@ func (s SongOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	fmt.Fprintln(tw)
 	fmt.Fprintln(tw, "Tracks")
 	fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tDuration:\tMp3")
-	for _, t := range s.Tracks {
+	tracks, capped := s.opts.capTracks(s.Tracks)
+	for _, t := range tracks {
 		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.ShowDate, t.VenueName, t.VenueLocation, t.Duration, t.Mp3)
 	}
+	if capped != "" {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, capped)
+	}
+	if p := s.TracksPage; p != nil {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, summarize(len(s.Tracks), "track", p))
+	}
 	return tw.Flush()
 }
 
 type ToursResponse struct {
-	Data []Tour `json:"data"`
+	TotalEntries int    `json:"total_entries"`
+	TotalPages   int    `json:"total_pages"`
+	Page         int    `json:"page"`
+	Data         []Tour `json:"data"`
 }
 
 func convertToursToOutput(tours []Tour) ToursOutput {
@@ -361,11 +1456,33 @@ func convertToursToOutput(tours []Tour) ToursOutput {
 }
 
 type ToursOutput struct {
-	Tours []TourOutput `json:"tours"`
+	TotalEntries int          `json:"total_entries"`
+	TotalPages   int          `json:"total_pages"`
+	CurrentPage  int          `json:"current_page"`
+	Tours        []TourOutput `json:"tours"`
+}
+
+func (t ToursOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage),
+		Data:       t.Tours,
+	}
+}
+
+func (t ToursOutput) summary() string {
+	return summarize(len(t.Tours), "tour", newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage))
+}
+
+func (t ToursOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(t.Tours))
+	for _, tour := range t.Tours {
+		rows = append(rows, []string{tour.Name, tour.StartsOn, tour.EndsOn, strconv.Itoa(tour.ShowsCount)})
+	}
+	return []string{"Name", "Starts On", "Ends On", "Shows Count"}, rows
 }
 
 func (t ToursOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Name:\tStarts On:\tEnds On:\tShows Count:")
 	for _, tour := range t.Tours {
 		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", tour.Name, tour.StartsOn, tour.EndsOn, tour.ShowsCount)
@@ -373,6 +1490,176 @@ func (t ToursOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	return tw.Flush()
 }
 
+// auditTours flags tours whose shows count doesn't match the number of
+// shows included in the response. Tours without shows aren't checked.
+func auditTours(tours []Tour) TourAuditOutput {
+	o := TourAuditOutput{Mismatches: []TourAudit{}}
+	for _, t := range tours {
+		if t.Shows == nil {
+			continue
+		}
+		o.Checked++
+		if t.ShowsCount != len(t.Shows) {
+			o.Mismatches = append(o.Mismatches, TourAudit{
+				Name:         t.Name,
+				Slug:         t.Slug,
+				ShowsCount:   t.ShowsCount,
+				ShowsPresent: len(t.Shows),
+			})
+		}
+	}
+	return o
+}
+
+// TourAudit is a tour whose shows count and shows don't agree.
+type TourAudit struct {
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ShowsCount   int    `json:"shows_count"`
+	ShowsPresent int    `json:"shows_present"`
+}
+
+type TourAuditOutput struct {
+	Checked    int         `json:"checked"`
+	Mismatches []TourAudit `json:"mismatches"`
+}
+
+func (t TourAuditOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	if len(t.Mismatches) != 0 {
+		fmt.Fprintln(tw, "Name:\tSlug:\tShows Count:\tShows Present:")
+		for _, m := range t.Mismatches {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", m.Name, m.Slug, m.ShowsCount, m.ShowsPresent)
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "%d of %d tours with shows have a mismatched shows count\n", len(t.Mismatches), t.Checked)
+	return tw.Flush()
+}
+
+// reportIncomplete lists the shows flagged incomplete or without any tracks.
+func reportIncomplete(shows []Show) IncompleteReportOutput {
+	o := IncompleteReportOutput{Checked: len(shows), Shows: []IncompleteShow{}}
+	for _, s := range shows {
+		var reason string
+		switch {
+		case s.Incomplete:
+			reason = "incomplete"
+		case len(s.Tracks) == 0:
+			reason = "no tracks"
+		default:
+			continue
+		}
+		show := convertShowToOutput(s)
+		o.Shows = append(o.Shows, IncompleteShow{
+			Date:          show.Date,
+			VenueName:     show.VenueName,
+			VenueLocation: show.VenueLocation,
+			Reason:        reason,
+		})
+	}
+	return o
+}
+
+// IncompleteShow is a show that is missing some or all of its audio.
+type IncompleteShow struct {
+	Date          string `json:"date"`
+	VenueName     string `json:"venue_name"`
+	VenueLocation string `json:"location"`
+	Reason        string `json:"reason"`
+}
+
+type IncompleteReportOutput struct {
+	Checked int              `json:"checked"`
+	Shows   []IncompleteShow `json:"shows"`
+}
+
+func (r IncompleteReportOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	if len(r.Shows) != 0 {
+		fmt.Fprintln(tw, "Date:\tVenue:\tLocation:\tReason:")
+		for _, s := range r.Shows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Date, s.VenueName, s.VenueLocation, s.Reason)
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "%d of %d shows are incomplete or missing audio\n", len(r.Shows), r.Checked)
+	return tw.Flush()
+}
+
+// LikeOutput is a show or track that was just liked (like shows 696).
+type LikeOutput struct {
+	Entity     string `json:"entity"`
+	ID         int    `json:"id"`
+	LikesCount int    `json:"likes_count,omitempty"`
+}
+
+func (l LikeOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	msg := fmt.Sprintf("liked %s %d", strings.TrimSuffix(l.Entity, "s"), l.ID)
+	if l.LikesCount != 0 {
+		msg += fmt.Sprintf(" (%d likes)", l.LikesCount)
+	}
+	_, err := fmt.Fprintln(w, msg)
+	return err
+}
+
+// ShareOutput is the phish.in page for an entity (--share).
+type ShareOutput struct {
+	URL string `json:"url"`
+}
+
+func (s ShareOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	_, err := fmt.Fprintln(w, s.URL)
+	return err
+}
+
+// AuthCheckOutput reports an accepted api key (auth-check). A rejected key
+// is an error instead, so scripts can check the exit code.
+type AuthCheckOutput struct {
+	Valid bool `json:"valid"`
+}
+
+func (a AuthCheckOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	_, err := fmt.Fprintln(w, "api key is valid")
+	return err
+}
+
+// CountOutput is the total number of entries for a list (--count).
+type CountOutput struct {
+	Count int `json:"count"`
+}
+
+func (c CountOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	_, err := fmt.Fprintln(w, c.Count)
+	return err
+}
+
+// EntityRefResponse holds the fields shared by the song, tour, venue, and
+// tag detail responses.
+type EntityRefResponse struct {
+	Data struct {
+		ID    int    `json:"id"`
+		Slug  string `json:"slug"`
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	} `json:"data"`
+}
+
+// ResolveOutput is an entity's id alongside its slug.
+type ResolveOutput struct {
+	Entity string `json:"entity"`
+	ID     int    `json:"id"`
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+}
+
+func (r ResolveOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "ID:\tSlug:\tName:")
+	fmt.Fprintf(tw, "%d\t%s\t%s\n", r.ID, r.Slug, r.Name)
+	return tw.Flush()
+}
+
 type TourResponse struct {
 	Data Tour `json:"data"`
 }
@@ -383,10 +1670,25 @@ type TourOutput struct {
 	StartsOn   string       `json:"starts_on"`
 	EndsOn     string       `json:"ends_on"`
 	Shows      []ShowOutput `json:"shows"`
+	// expanded is set when Shows have their tracks (tours -s -v --expand)
+	expanded bool
+	opts     printOptions
+}
+
+func (t TourOutput) withOptions(o printOptions) PrettyPrinter {
+	if o.reformatsDurations() {
+		shows := make([]ShowOutput, len(t.Shows))
+		for i, show := range t.Shows {
+			shows[i] = o.withDurations(show)
+		}
+		t.Shows = shows
+	}
+	t.opts = o
+	return t
 }
 
 func (t TourOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Name:\tStarts On:\tEnds On:\tShow Count:")
 	fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", t.Name, t.StartsOn, t.EndsOn, t.ShowsCount)
 	fmt.Fprintln(tw)
@@ -396,7 +1698,86 @@ func (t TourOutput) PrettyPrint(w io.Writer, verbose bool) error {
 		r := trueAsYes(show.Remastered)
 		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", show.ID, show.Date, show.VenueName, show.VenueLocation, show.Duration, sbd, r)
 	}
-	return tw.Flush()
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !verbose || !t.expanded {
+		return nil
+	}
+	for _, show := range t.Shows {
+		fmt.Fprintln(w)
+		if err := show.withOptions(t.opts).PrettyPrint(w, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TourDiffOutput is the songs played on one tour but not the other
+// (tour-diff).
+type TourDiffOutput struct {
+	Tour          string   `json:"tour"`
+	Against       string   `json:"against"`
+	OnlyInTour    []string `json:"only_in_tour"`
+	OnlyInAgainst []string `json:"only_in_against"`
+	// Shared is how many songs both tours played
+	Shared int `json:"shared"`
+}
+
+// diffTours compares the song titles played across the shows of tour and
+// against, listing each side's songs in alphabetical order.
+func diffTours(tour, against TourOutput) TourDiffOutput {
+	a, b := tourSongs(tour), tourSongs(against)
+	o := TourDiffOutput{
+		Tour:          tour.Name,
+		Against:       against.Name,
+		OnlyInTour:    []string{},
+		OnlyInAgainst: []string{},
+	}
+	for title := range a {
+		if b[title] {
+			o.Shared++
+			continue
+		}
+		o.OnlyInTour = append(o.OnlyInTour, title)
+	}
+	for title := range b {
+		if !a[title] {
+			o.OnlyInAgainst = append(o.OnlyInAgainst, title)
+		}
+	}
+	sort.Strings(o.OnlyInTour)
+	sort.Strings(o.OnlyInAgainst)
+	return o
+}
+
+// tourSongs is the set of song titles played on a tour.
+func tourSongs(tour TourOutput) map[string]bool {
+	songs := make(map[string]bool)
+	for _, show := range tour.Shows {
+		for _, t := range show.Tracks {
+			songs[t.Title] = true
+		}
+	}
+	return songs
+}
+
+func (d TourDiffOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	for _, side := range []struct {
+		name  string
+		songs []string
+	}{
+		{d.Tour, d.OnlyInTour},
+		{d.Against, d.OnlyInAgainst},
+	} {
+		fmt.Fprintf(w, "Only in %s (%d):\n", side.name, len(side.songs))
+		for _, song := range side.songs {
+			fmt.Fprintln(w, song)
+		}
+		fmt.Fprintln(w)
+	}
+	_, err := fmt.Fprintf(w, "Played on both: %d\n", d.Shared)
+	return err
 }
 
 type VenuesResponse struct {
@@ -413,8 +1794,27 @@ type VenuesOutput struct {
 	Venues       []VenueOutput `json:"venues"`
 }
 
+func (v VenuesOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(v.TotalEntries, v.TotalPages, v.CurrentPage),
+		Data:       v.Venues,
+	}
+}
+
+func (v VenuesOutput) summary() string {
+	return summarize(len(v.Venues), "venue", newPagination(v.TotalEntries, v.TotalPages, v.CurrentPage))
+}
+
+func (v VenuesOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(v.Venues))
+	for _, venue := range v.Venues {
+		rows = append(rows, []string{venue.Name, venue.Location, strconv.Itoa(venue.ShowsCount)})
+	}
+	return []string{"Venue", "Location", "Show Count"}, rows
+}
+
 func (v VenuesOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Venue:\tLocation:\tShow Count:")
 	for _, venue := range v.Venues {
 		fmt.Fprintf(tw, "%s\t%s\t%d\n", venue.Name, venue.Location, venue.ShowsCount)
@@ -426,6 +1826,140 @@ func (v VenuesOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	return tw.Flush()
 }
 
+// venueLocations are the ways venues can be grouped with --by-city,
+// --by-state, or --by-country.
+var venueLocations = map[string]func(VenueOutput) string{
+	"city": func(v VenueOutput) string {
+		return strings.Join(nonEmpty(v.City, v.State), ", ")
+	},
+	"state": func(v VenueOutput) string {
+		return strings.Join(nonEmpty(v.State, v.Country), ", ")
+	},
+	"country": func(v VenueOutput) string { return v.Country },
+}
+
+func nonEmpty(ss ...string) []string {
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// groupVenuesByLocation totals the venues and their shows for each city,
+// state, or country (by). Locations with the most shows come first.
+func groupVenuesByLocation(venues []VenueOutput, by string) VenuesByLocationOutput {
+	location := venueLocations[by]
+	byLocation := make(map[string]*LocationVenues)
+	var locations []*LocationVenues
+	for _, v := range venues {
+		name := location(v)
+		if name == "" {
+			name = "Unknown"
+		}
+		l, ok := byLocation[name]
+		if !ok {
+			l = &LocationVenues{Location: name}
+			byLocation[name] = l
+			locations = append(locations, l)
+		}
+		l.Venues++
+		l.Shows += v.ShowsCount
+	}
+	sort.SliceStable(locations, func(i, j int) bool {
+		if locations[i].Shows != locations[j].Shows {
+			return locations[i].Shows > locations[j].Shows
+		}
+		return locations[i].Location < locations[j].Location
+	})
+	o := VenuesByLocationOutput{By: by, Locations: make([]LocationVenues, 0, len(locations))}
+	for _, l := range locations {
+		o.Locations = append(o.Locations, *l)
+	}
+	return o
+}
+
+// dedupeVenues collapses venues that are the same place under another name:
+// venues at the same location where one's name matches the other's name or
+// one of its other names. The first venue listed keeps its name, picking up
+// the rest as other names, and the show dates of each are merged.
+func dedupeVenues(venues []VenueOutput) []VenueOutput {
+	deduped := make([]VenueOutput, 0, len(venues))
+	for _, v := range venues {
+		i := sameVenue(deduped, v)
+		if i < 0 {
+			v.OtherNames = append([]string(nil), v.OtherNames...)
+			v.ShowDates = append([]string(nil), v.ShowDates...)
+			deduped = append(deduped, v)
+			continue
+		}
+		d := &deduped[i]
+		for _, name := range append([]string{v.Name}, v.OtherNames...) {
+			if !strings.EqualFold(name, d.Name) && !containsFold(d.OtherNames, name) {
+				d.OtherNames = append(d.OtherNames, name)
+			}
+		}
+		d.ShowsCount += v.ShowsCount
+		for _, date := range v.ShowDates {
+			// a show listed under both venues only counts once
+			if containsFold(d.ShowDates, date) {
+				d.ShowsCount--
+				continue
+			}
+			d.ShowDates = append(d.ShowDates, date)
+		}
+		sort.Strings(d.ShowDates)
+	}
+	return deduped
+}
+
+// sameVenue returns the index of the venue in venues that v duplicates, or
+// -1 if there isn't one.
+func sameVenue(venues []VenueOutput, v VenueOutput) int {
+	for i, d := range venues {
+		if !strings.EqualFold(d.Location, v.Location) {
+			continue
+		}
+		if strings.EqualFold(d.Name, v.Name) || containsFold(d.OtherNames, v.Name) || containsFold(v.OtherNames, d.Name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocationVenues totals the venues in one city, state, or country.
+type LocationVenues struct {
+	Location string `json:"location"`
+	Venues   int    `json:"venues"`
+	Shows    int    `json:"shows"`
+}
+
+type VenuesByLocationOutput struct {
+	By        string           `json:"by"`
+	Locations []LocationVenues `json:"locations"`
+}
+
+func (v VenuesByLocationOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	header := strings.ToUpper(v.By[:1]) + v.By[1:] + ":"
+	fmt.Fprintf(tw, "%s\tVenues:\tShows:\n", header)
+	for _, l := range v.Locations {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", l.Location, l.Venues, l.Shows)
+	}
+	return tw.Flush()
+}
+
 type VenueResponse struct {
 	Data Venue `json:"data"`
 }
@@ -436,7 +1970,60 @@ func convertVenueToOutput(venue Venue) VenueOutput {
 		Location:   venue.Location,
 		ShowsCount: venue.ShowsCount,
 		ShowDates:  venue.ShowDates,
+		OtherNames: venue.OtherNames,
+		Latitude:   venue.Latitude,
+		Longitude:  venue.Longitude,
+		City:       venue.City,
+		State:      venue.State,
+		Country:    venue.Country,
+	}
+}
+
+// VenueSongsOutput is the songs played across a venue's shows (venues -s
+// --songs).
+type VenueSongsOutput struct {
+	Venue string `json:"venue"`
+	// Shows is how many of the venue's shows were tallied
+	Shows int         `json:"shows"`
+	Songs []VenueSong `json:"songs"`
+}
+
+// VenueSong is a song played at a venue and how many times it was.
+type VenueSong struct {
+	Title       string `json:"title"`
+	TimesPlayed int    `json:"times_played"`
+}
+
+// tallyVenueSongs counts each song played across shows, most played first
+// (ties in title order).
+func tallyVenueSongs(venue string, shows []ShowOutput) VenueSongsOutput {
+	counts := make(map[string]int)
+	for _, show := range shows {
+		for _, t := range show.Tracks {
+			counts[t.Title]++
+		}
+	}
+	songs := make([]VenueSong, 0, len(counts))
+	for title, n := range counts {
+		songs = append(songs, VenueSong{Title: title, TimesPlayed: n})
 	}
+	sort.Slice(songs, func(i, j int) bool {
+		if songs[i].TimesPlayed != songs[j].TimesPlayed {
+			return songs[i].TimesPlayed > songs[j].TimesPlayed
+		}
+		return songs[i].Title < songs[j].Title
+	})
+	return VenueSongsOutput{Venue: venue, Shows: len(shows), Songs: songs}
+}
+
+func (v VenueSongsOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	fmt.Fprintf(w, "%d songs across %d shows at %s\n\n", len(v.Songs), v.Shows, v.Venue)
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "Song:\tTimes Played:")
+	for _, song := range v.Songs {
+		fmt.Fprintf(tw, "%s\t%d\n", song.Title, song.TimesPlayed)
+	}
+	return tw.Flush()
 }
 
 type VenueOutput struct {
@@ -444,21 +2031,69 @@ type VenueOutput struct {
 	Location   string   `json:"location"`
 	ShowsCount int      `json:"shows_count"`
 	ShowDates  []string `json:"show_dates"`
+	OtherNames []string `json:"other_names,omitempty"`
+	Latitude   float64  `json:"latitude,omitempty"`
+	Longitude  float64  `json:"longitude,omitempty"`
+	City       string   `json:"city,omitempty"`
+	State      string   `json:"state,omitempty"`
+	Country    string   `json:"country,omitempty"`
+	opts       printOptions
+}
+
+func (v VenueOutput) withOptions(o printOptions) PrettyPrinter {
+	v.opts = o
+	return v
 }
 
 func (v VenueOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "Venue:\tLocation:\tShow Count:")
 	fmt.Fprintf(tw, "%s\t%s\t%d\n", v.Name, v.Location, v.ShowsCount)
 	fmt.Fprintln(tw)
 	if len(v.ShowDates) == 0 {
+		v.opts.printNone(tw, "Show Dates:")
 		return tw.Flush()
 	}
-	fmt.Fprintln(tw, "Show Dates")
-	for _, d := range v.ShowDates {
-		fmt.Fprintln(tw, d)
+	fmt.Fprintln(tw, "Show Dates")
+	for _, d := range v.ShowDates {
+		fmt.Fprintln(tw, d)
+	}
+	return tw.Flush()
+}
+
+// skippedJSON discards the value it's decoded from, without allocating it.
+type skippedJSON struct{}
+
+func (*skippedJSON) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// showHead is a Show decoded without its tracks. The outer Tracks field
+// takes precedence over the embedded one.
+type showHead struct {
+	Show
+	Tracks skippedJSON `json:"tracks"`
+}
+
+// ShowHeadsResponse is a ShowsResponse decoded without the tracks (--head-only).
+type ShowHeadsResponse struct {
+	TotalEntries int        `json:"total_entries"`
+	TotalPages   int        `json:"total_pages"`
+	Page         int        `json:"page"`
+	Data         []showHead `json:"data"`
+}
+
+func (r ShowHeadsResponse) shows() ShowsResponse {
+	shows := make([]Show, 0, len(r.Data))
+	for _, h := range r.Data {
+		shows = append(shows, h.Show)
+	}
+	return ShowsResponse{
+		TotalEntries: r.TotalEntries,
+		TotalPages:   r.TotalPages,
+		Page:         r.Page,
+		Data:         shows,
 	}
-	return tw.Flush()
 }
 
 type ShowsResponse struct {
@@ -484,16 +2119,107 @@ type ShowsOutput struct {
 	TotalPages   int          `json:"total_pages"`
 	CurrentPage  int          `json:"current_page"`
 	Shows        []ShowOutput `json:"shows"`
+	opts         printOptions
+	// tourNames, when set (--mark-tours), names the tour of each show so a
+	// line can mark where each tour starts
+	tourNames map[int]string
+}
+
+// tourMarker starts the last cell of a line naming a tour. The line's other
+// cells are empty, so the table stays aligned across it, and everything up
+// to the marker is trimmed once the table is written.
+const tourMarker = "\x00tour\x00"
+
+// printTourMarker writes a line naming the tour of the i-th show if it
+// starts a tour (i.e. the previous show was from another one). cells is
+// the number of cells in each row of the table.
+func (s ShowsOutput) printTourMarker(w io.Writer, i, cells int) {
+	if s.tourNames == nil || (i > 0 && s.Shows[i].TourID == s.Shows[i-1].TourID) {
+		return
+	}
+	name, ok := s.tourNames[s.Shows[i].TourID]
+	if !ok {
+		name = "Unknown tour"
+	}
+	fmt.Fprintf(w, "%s%s-- %s --\n", strings.Repeat("\t", cells-1), tourMarker, name)
+}
+
+func (s ShowsOutput) withOptions(o printOptions) PrettyPrinter {
+	if o.reformatsDurations() {
+		shows := make([]ShowOutput, len(s.Shows))
+		for i, show := range s.Shows {
+			shows[i] = o.withDurations(show)
+		}
+		s.Shows = shows
+	}
+	s.opts = o
+	return s
+}
+
+func (s ShowsOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(s.TotalEntries, s.TotalPages, s.CurrentPage),
+		Data:       s.Shows,
+	}
+}
+
+func (s ShowsOutput) summary() string {
+	return summarize(len(s.Shows), "show", newPagination(s.TotalEntries, s.TotalPages, s.CurrentPage))
 }
 
 func (s ShowsOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	if s.tourNames == nil {
+		return s.printTable(w, verbose)
+	}
+	var buf bytes.Buffer
+	if err := s.printTable(&buf, verbose); err != nil {
+		return err
+	}
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if i := strings.Index(line, tourMarker); i >= 0 {
+			line = line[i+len(tourMarker):]
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tsv uses the verbose columns, so --columns and --links pick them.
+func (s ShowsOutput) tsv() ([]string, [][]string) {
+	cols := s.opts.verboseShowColumns()
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = strings.TrimSuffix(col.header, ":")
+	}
+	rows := make([][]string, 0, len(s.Shows))
+	for _, show := range s.Shows {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = col.value(show)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+func (s ShowsOutput) printTable(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
 	if verbose {
-		fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tDuration:\tSoundboard:\tRemastered:")
-		for _, show := range s.Shows {
-			sbd := trueAsYes(show.Sbd)
-			r := trueAsYes(show.Remastered)
-			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", show.ID, show.Date, show.VenueName, show.VenueLocation, show.Duration, sbd, r)
+		cols := s.opts.verboseShowColumns()
+		headers := make([]string, len(cols))
+		for i, col := range cols {
+			headers[i] = col.paint(s.opts.theme, col.header)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for i, show := range s.Shows {
+			s.printTourMarker(tw, i, len(cols))
+			cells := make([]string, len(cols))
+			for i, col := range cols {
+				cells[i] = col.paint(s.opts.theme, col.value(show))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
 		}
 		// the year details response prints a ShowsOutput but won't have any entries, for example
 		if s.TotalEntries != 0 {
@@ -502,9 +2228,13 @@ func (s ShowsOutput) PrettyPrint(w io.Writer, verbose bool) error {
 		}
 		return tw.Flush()
 	}
-	fmt.Fprintln(tw, "Date:\tVenue:\tLocation:\tDuration:")
-	for _, show := range s.Shows {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", show.Date, show.VenueName, show.VenueLocation, show.Duration)
+	header := fmt.Sprintf("%s\t%s\tLocation:\t%s", s.opts.theme.date("Date:"), s.opts.theme.venue("Venue:"), s.opts.theme.duration("Duration:"))
+	header = s.opts.withLinkColumn(header, "Link:")
+	fmt.Fprintln(tw, header)
+	for i, show := range s.Shows {
+		s.printTourMarker(tw, i, strings.Count(header, "\t")+1)
+		row := fmt.Sprintf("%s\t%s\t%s\t%s", s.opts.theme.date(show.Date), s.opts.theme.venue(show.VenueName), show.VenueLocation, s.opts.theme.duration(show.Duration))
+		fmt.Fprintln(tw, s.opts.withLinkColumn(row, showLink(show.Date)))
 	}
 	// the year details response prints a ShowsOutput but won't have any entries, for example
 	if s.TotalEntries != 0 {
@@ -523,11 +2253,19 @@ func convertShowToOutput(show Show) ShowOutput {
 		ID:            show.ID,
 		Date:          show.Date,
 		Duration:      convertMillisecondToConcertDuration(int64(show.Duration)),
+		durationMS:    int64(show.Duration),
 		Sbd:           show.Sbd,
 		Remastered:    show.Remastered,
 		Tags:          show.Tags,
 		VenueName:     show.VenueName,
 		VenueLocation: show.Location,
+		TourID:        show.TourID,
+		VenueID:       show.VenueID,
+		Cover:         show.CoverArt,
+	}
+	// the venue id is sometimes only on the embedded Venue
+	if o.VenueID == 0 {
+		o.VenueID = show.Venue.ID
 	}
 	o.Venue = convertVenueToOutput(show.Venue)
 	tracks := convertTracksToOutput(show.Tracks)
@@ -571,45 +2309,77 @@ type ShowOutput struct {
 	Venue         VenueOutput   `json:"venue"`
 	VenueName     string        `json:"venue_name"`
 	VenueLocation string        `json:"location"`
+	TourID        int           `json:"tour_id"`
+	VenueID       int           `json:"venue_id"`
 	Tracks        []TrackOutput `json:"tracks"`
+	Cover         string        `json:"cover,omitempty"`
+	durationMS    int64
+	// eras places a show without cover art in its era for the placeholder
+	eras ErasOutput
+	opts printOptions
+}
+
+// cover is the show's cover art url, or a placeholder naming its era when
+// phish.in doesn't have any.
+func (s ShowOutput) cover() string {
+	if s.Cover != "" {
+		return s.Cover
+	}
+	return fmt.Sprintf("none (era %s)", s.eras.eraOf(s.Date))
+}
+
+// eraOf returns the era (e.g. 3.0) that lists the year of a show date, or
+// unknown if none does (e.g. a year of the hiatus).
+func (e ErasOutput) eraOf(date string) string {
+	year, err := strconv.Atoi(strings.SplitN(date, "-", 2)[0])
+	if err != nil {
+		return "unknown"
+	}
+	for _, name := range e.names() {
+		for _, bucket := range e[name] {
+			first, last, err := parseYearBucket(bucket)
+			if err == nil && year >= first && year <= last {
+				return name
+			}
+		}
+	}
+	return "unknown"
+}
+
+func (s ShowOutput) withOptions(o printOptions) PrettyPrinter {
+	s = o.withDurations(s)
+	s.opts = o
+	return s
 }
 
 func (s ShowOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
+	var capped string
+	s.Tracks, capped = s.opts.capTracks(s.Tracks)
 	if verbose {
-		fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tDuration:\tSoundboard:\tRemastered:")
+		header := fmt.Sprintf("ID:\t%s\t%s\tLocation:\t%s\tSoundboard:\tRemastered:", s.opts.theme.date("Date:"), s.opts.theme.venue("Venue:"), s.opts.theme.duration("Duration:"))
+		fmt.Fprintln(tw, s.opts.withLinkColumn(header, "Link:"))
 		sbd := trueAsYes(s.Sbd)
 		r := trueAsYes(s.Remastered)
-		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Date, s.VenueName, s.VenueLocation, s.Duration, sbd, r)
+		row := fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%s\t%s", s.ID, s.opts.theme.date(s.Date), s.opts.theme.venue(s.VenueName), s.VenueLocation, s.opts.theme.duration(s.Duration), sbd, r)
+		fmt.Fprintln(tw, s.opts.withLinkColumn(row, showLink(s.Date)))
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "Cover: %s\n", s.cover())
 		fmt.Fprintln(tw)
 		if len(s.Tags) != 0 {
 			fmt.Fprintln(tw, "Show Tags:")
 			tagInfo := convertTagsToString(s.Tags)
 			fmt.Fprintln(tw, tagInfo)
 			fmt.Fprintln(tw)
+		} else {
+			s.opts.printNone(tw, "Show Tags:")
 		}
 		// should always have tracks but worth a check
 		if len(s.Tracks) == 0 {
+			s.opts.printNone(tw, "Tracks:")
 			return tw.Flush()
 		}
-		longestTitleLen := 0
-		for _, t := range s.Tracks {
-			if len(t.Title) > longestTitleLen {
-				longestTitleLen = len(t.Title)
-			}
-		}
-		fmt.Fprintln(tw, s.Tracks[0].SetName)
-		for i, t := range s.Tracks {
-			if i > 1 && t.SetName != s.Tracks[i-1].SetName {
-				fmt.Fprintln(tw)
-				fmt.Fprintln(tw, s.Tracks[i].SetName)
-			}
-			// we want the title - duration distance the same
-			// across sets, so make all titles the same length
-			toAdd := longestTitleLen - len(t.Title)
-			title := t.Title + strings.Repeat(" ", toAdd)
-			fmt.Fprintf(tw, "%s\t%s\n", title, t.Duration)
-		}
+		s.printSetlist(tw, true)
 		fmt.Fprintln(tw)
 		fmt.Fprintln(tw, "Track Info:")
 		for _, t := range s.Tracks {
@@ -621,32 +2391,154 @@ func (s ShowOutput) PrettyPrint(w io.Writer, verbose bool) error {
 			}
 			fmt.Fprintln(tw)
 		}
+		if capped != "" {
+			fmt.Fprintln(tw, capped)
+		}
 		return tw.Flush()
 	}
-	fmt.Fprintln(tw, "Date:\tVenue:\tLocation:")
-	fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Date, s.VenueName, s.VenueLocation)
+	header := fmt.Sprintf("%s\t%s\tLocation:", s.opts.theme.date("Date:"), s.opts.theme.venue("Venue:"))
+	fmt.Fprintln(tw, s.opts.withLinkColumn(header, "Link:"))
+	row := fmt.Sprintf("%s\t%s\t%s", s.opts.theme.date(s.Date), s.opts.theme.venue(s.VenueName), s.VenueLocation)
+	fmt.Fprintln(tw, s.opts.withLinkColumn(row, showLink(s.Date)))
 	fmt.Fprintln(tw)
 	// should always have tracks but worth a check
 	if len(s.Tracks) == 0 {
+		s.opts.printNone(tw, "Tracks:")
 		return tw.Flush()
 	}
+	s.printSetlist(tw, false)
+	if capped != "" {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, capped)
+	}
+	return tw.Flush()
+}
+
+// printSetlist lists the show's tracks under a header for each set, or with
+// --merge-sets, as one numbered list (verbose adds each track's set).
+func (s ShowOutput) printSetlist(w io.Writer, verbose bool) {
+	tracks := s.opts.setlist(s.Tracks)
+	if s.opts.mergeSets {
+		for i, t := range tracks {
+			row := fmt.Sprintf("%d.\t%s\t%s", i+1, t.Title, s.opts.theme.duration(t.Duration))
+			if verbose {
+				row += "\t" + t.SetName
+			}
+			fmt.Fprintln(w, row)
+		}
+		return
+	}
 	longestTitleLen := 0
-	for _, t := range s.Tracks {
+	for _, t := range tracks {
 		if len(t.Title) > longestTitleLen {
 			longestTitleLen = len(t.Title)
 		}
 	}
-	fmt.Fprintln(tw, s.Tracks[0].SetName)
-	for i, t := range s.Tracks {
-		if i > 1 && t.SetName != s.Tracks[i-1].SetName {
-			fmt.Fprintln(tw)
-			fmt.Fprintln(tw, s.Tracks[i].SetName)
+	fmt.Fprintln(w, tracks[0].SetName)
+	for i, t := range tracks {
+		if i > 1 && t.SetName != tracks[i-1].SetName {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, tracks[i].SetName)
 		}
+		// we want the title - duration distance the same
+		// across sets, so make all titles the same length
 		toAdd := longestTitleLen - len(t.Title)
 		title := t.Title + strings.Repeat(" ", toAdd)
-		fmt.Fprintf(tw, "%s\t%s\n", title, t.Duration)
+		fmt.Fprintf(w, "%s\t%s\n", title, s.opts.theme.duration(t.Duration))
 	}
-	return tw.Flush()
+}
+
+// sortTracks orders tracks by attr (duration or title), keeping setlist
+// order for ties.
+func sortTracks(tracks []TrackOutput, attr string, desc bool) {
+	less := func(a, b TrackOutput) bool {
+		if attr == "title" {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+		return a.durationMS < b.durationMS
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		if desc {
+			return less(tracks[j], tracks[i])
+		}
+		return less(tracks[i], tracks[j])
+	})
+}
+
+// SetOutput is a single set (or encore) and its tracks.
+type SetOutput struct {
+	Name   string        `json:"name"`
+	Tracks []TrackOutput `json:"tracks"`
+}
+
+// groupTracksBySet splits tracks into sets, starting a new set whenever
+// the set name changes, so the show's running order is preserved.
+func groupTracksBySet(tracks []TrackOutput) []SetOutput {
+	var sets []SetOutput
+	for i, t := range tracks {
+		if i == 0 || t.SetName != tracks[i-1].SetName {
+			sets = append(sets, SetOutput{Name: t.SetName})
+		}
+		last := &sets[len(sets)-1]
+		last.Tracks = append(last.Tracks, t)
+	}
+	return sets
+}
+
+func convertShowToNestedOutput(show ShowOutput) NestedShowOutput {
+	return NestedShowOutput{
+		ID:            show.ID,
+		Date:          show.Date,
+		Duration:      show.Duration,
+		Sbd:           show.Sbd,
+		Remastered:    show.Remastered,
+		Tags:          show.Tags,
+		Venue:         show.Venue,
+		VenueName:     show.VenueName,
+		VenueLocation: show.VenueLocation,
+		TourID:        show.TourID,
+		VenueID:       show.VenueID,
+		Sets:          groupTracksBySet(show.Tracks),
+		Cover:         show.Cover,
+	}
+}
+
+// NestedShowOutput is a ShowOutput with its tracks grouped into sets.
+type NestedShowOutput struct {
+	ID            int         `json:"id"`
+	Date          string      `json:"date"`
+	Duration      string      `json:"duration"`
+	Sbd           bool        `json:"sbd"`
+	Remastered    bool        `json:"remastered"`
+	Tags          []Tag       `json:"tags"`
+	Venue         VenueOutput `json:"venue"`
+	VenueName     string      `json:"venue_name"`
+	VenueLocation string      `json:"location"`
+	TourID        int         `json:"tour_id"`
+	VenueID       int         `json:"venue_id"`
+	Sets          []SetOutput `json:"sets"`
+	Cover         string      `json:"cover,omitempty"`
+}
+
+func (n NestedShowOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	s := ShowOutput{
+		ID:            n.ID,
+		Date:          n.Date,
+		Duration:      n.Duration,
+		Sbd:           n.Sbd,
+		Remastered:    n.Remastered,
+		Tags:          n.Tags,
+		Venue:         n.Venue,
+		VenueName:     n.VenueName,
+		VenueLocation: n.VenueLocation,
+		TourID:        n.TourID,
+		VenueID:       n.VenueID,
+		Cover:         n.Cover,
+	}
+	for _, set := range n.Sets {
+		s.Tracks = append(s.Tracks, set.Tracks...)
+	}
+	return s.PrettyPrint(w, verbose)
 }
 
 type ShowOnDateResponse struct {
@@ -685,11 +2577,56 @@ type TracksOutput struct {
 	Tracks       []TrackOutput `json:"tracks"`
 }
 
+func (t TracksOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage),
+		Data:       t.Tracks,
+	}
+}
+
+func (t TracksOutput) summary() string {
+	return summarize(len(t.Tracks), "track", newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage))
+}
+
+func (t TracksOutput) withOptions(o printOptions) PrettyPrinter {
+	if o.reformatsDurations() {
+		tracks := make([]TrackOutput, len(t.Tracks))
+		for i, track := range t.Tracks {
+			track.Duration = o.formatDuration(track.Duration, track.durationMS)
+			tracks[i] = track
+		}
+		t.Tracks = tracks
+	}
+	return t
+}
+
+func (t TracksOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(t.Tracks))
+	for _, track := range t.Tracks {
+		rows = append(rows, []string{strconv.Itoa(track.ID), track.ShowDate, track.VenueName, track.VenueLocation, track.Title, track.Duration, track.SetName, track.Mp3})
+	}
+	return []string{"ID", "Date", "Venue", "Location", "Title", "Duration", "Set", "Mp3"}, rows
+}
+
 func (t TracksOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
-	fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tTitle:\tMp3:")
+	tw := newTabWriter(w)
+	if t.withShowContext() {
+		fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tTitle:\tSet:\tShow Tracks:\tMp3:")
+	} else {
+		fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tTitle:\tMp3:")
+	}
 	for _, track := range t.Tracks {
-		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", track.ID, track.ShowDate, track.VenueName, track.VenueLocation, track.Title, track.Mp3)
+		if !t.withShowContext() {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", track.ID, track.ShowDate, track.VenueName, track.VenueLocation, track.Title, track.Mp3)
+			continue
+		}
+		// e.g. Set 2 #3, blank when the show didn't list the track
+		var set, showTracks string
+		if sc := track.ShowContext; sc != nil {
+			set = fmt.Sprintf("%s #%d", track.SetName, sc.SetPosition)
+			showTracks = strconv.Itoa(sc.ShowTracks)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", track.ID, track.ShowDate, track.VenueName, track.VenueLocation, track.Title, set, showTracks, track.Mp3)
 	}
 	fmt.Fprintln(tw)
 	if t.TotalEntries != 0 {
@@ -698,6 +2635,17 @@ func (t TracksOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	return tw.Flush()
 }
 
+// withShowContext reports whether any track was placed in its show, to
+// add the columns for it.
+func (t TracksOutput) withShowContext() bool {
+	for _, track := range t.Tracks {
+		if track.ShowContext != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type TrackResponse struct {
 	Data Track `json:"data"`
 }
@@ -710,9 +2658,11 @@ func convertTrackToOutput(track Track) TrackOutput {
 		VenueLocation: track.VenueLocation,
 		Title:         track.Title,
 		Duration:      convertMillisecondToConcertDuration(int64(track.Duration)),
+		durationMS:    int64(track.Duration),
 		SetName:       track.SetName,
 		Tags:          track.Tags,
 		Mp3:           track.Mp3,
+		WaveformImage: track.WaveformImage,
 	}
 }
 
@@ -726,40 +2676,133 @@ type TrackOutput struct {
 	SetName       string `json:"set_name"`
 	Tags          []Tag  `json:"tags"`
 	Mp3           string `json:"mp3"`
+	WaveformImage string `json:"waveform_image"`
+	// ShowContext places the track in its show (tracks --with-show-context)
+	ShowContext *TrackShowContext `json:"show_context,omitempty"`
+	durationMS  int64
+	opts        printOptions
+}
+
+// TrackShowContext is where a track falls in its show.
+type TrackShowContext struct {
+	// SetPosition is the track's place in its set, starting from 1
+	SetPosition int `json:"set_position"`
+	ShowTracks  int `json:"show_tracks"`
+}
+
+// newTrackShowContext places the track with id in show, or returns nil if
+// the show doesn't have it.
+func newTrackShowContext(show ShowOutput, id int) *TrackShowContext {
+	position := 0
+	for i, t := range show.Tracks {
+		if i == 0 || t.SetName != show.Tracks[i-1].SetName {
+			position = 0
+		}
+		position++
+		if t.ID == id {
+			return &TrackShowContext{SetPosition: position, ShowTracks: len(show.Tracks)}
+		}
+	}
+	return nil
+}
+
+func (t TrackOutput) withOptions(o printOptions) PrettyPrinter {
+	t.Duration = o.formatDuration(t.Duration, t.durationMS)
+	t.opts = o
+	return t
 }
 
 func (t TrackOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	fmt.Fprintln(tw, "ID:\tDate:\tVenue:\tLocation:\tTitle:\tDuration\tSet\tMp3")
 	fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.ShowDate, t.VenueName, t.VenueLocation, t.Title, t.Duration, t.SetName, t.Mp3)
 	fmt.Fprintln(tw)
+	if verbose && t.WaveformImage != "" {
+		fmt.Fprintf(tw, "Waveform: %s\n", t.WaveformImage)
+		fmt.Fprintln(tw)
+	}
 	if len(t.Tags) != 0 {
 		fmt.Fprintln(tw, "Tags")
 		fmt.Fprintln(tw, "Name:\tGroup:\tNotes:")
 		for _, tag := range t.Tags {
 			fmt.Fprintf(tw, "%s\t%s\t%s\n", tag.Name, tag.Group, tag.Notes)
 		}
+	} else {
+		t.opts.printNone(tw, "Tags:")
 	}
 	return tw.Flush()
 }
 
 type TagsResponse struct {
-	Data []TagListItem `json:"data"`
+	TotalEntries int           `json:"total_entries"`
+	TotalPages   int           `json:"total_pages"`
+	Page         int           `json:"page"`
+	Data         []TagListItem `json:"data"`
 }
 
 type TagsOutput struct {
-	Tags []TagListItemOutput `json:"tags"`
+	TotalEntries int                 `json:"total_entries"`
+	TotalPages   int                 `json:"total_pages"`
+	CurrentPage  int                 `json:"current_page"`
+	Tags         []TagListItemOutput `json:"tags"`
+	opts         printOptions
+}
+
+func (t TagsOutput) withOptions(o printOptions) PrettyPrinter {
+	t.opts = o
+	return t
+}
+
+func (t TagsOutput) envelope() JSONEnvelope {
+	return JSONEnvelope{
+		Pagination: newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage),
+		Data:       t.Tags,
+	}
+}
+
+func (t TagsOutput) summary() string {
+	return summarize(len(t.Tags), "tag", newPagination(t.TotalEntries, t.TotalPages, t.CurrentPage))
+}
+
+func (t TagsOutput) tsv() ([]string, [][]string) {
+	rows := make([][]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		rows = append(rows, []string{tag.Name, tag.Group, tag.Description, tag.Color})
+	}
+	return []string{"Name", "Group", "Description", "Color"}, rows
 }
 
 func (t TagsOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
-	fmt.Fprintln(tw, "Name:\tDescription:\tGroup:")
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, t.opts.tagHeader(verbose))
 	for _, tag := range t.Tags {
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", tag.Name, tag.Description, tag.Group)
+		fmt.Fprintln(tw, t.opts.tagRow(tag, verbose))
 	}
 	return tw.Flush()
 }
 
+// tagHeader is the header for the tag tables. The hex color gets its own
+// column with -v, unless color is on and it tints the name instead.
+func (o printOptions) tagHeader(verbose bool) string {
+	if o.theme != nil {
+		return tintCell("", "Name:") + "\tDescription:\tGroup:"
+	}
+	if verbose {
+		return "Name:\tDescription:\tGroup:\tColor:"
+	}
+	return "Name:\tDescription:\tGroup:"
+}
+
+func (o printOptions) tagRow(t TagListItemOutput, verbose bool) string {
+	if o.theme != nil {
+		return fmt.Sprintf("%s\t%s\t%s", tintCell(t.Color, t.Name), t.Description, t.Group)
+	}
+	if verbose {
+		return fmt.Sprintf("%s\t%s\t%s\t%s", t.Name, t.Description, t.Group, t.Color)
+	}
+	return fmt.Sprintf("%s\t%s\t%s", t.Name, t.Description, t.Group)
+}
+
 type TagResponse struct {
 	Data TagListItem `json:"data"`
 }
@@ -769,6 +2812,7 @@ func convertTagListItemToOutput(t TagListItem) TagListItemOutput {
 		Name:        t.Name,
 		Group:       t.Group,
 		Description: t.Description,
+		Color:       t.Color,
 		ShowIds:     t.ShowIds,
 		TrackIds:    t.TrackIds,
 	}
@@ -779,14 +2823,22 @@ type TagListItemOutput struct {
 	Name        string `json:"name"`
 	Group       string `json:"group"`
 	Description string `json:"description"`
-	ShowIds     []int  `json:"show_ids"`
-	TrackIds    []int  `json:"track_ids"`
+	// Color is the hex color phish.in uses for the tag, e.g. #888888
+	Color    string `json:"color"`
+	ShowIds  []int  `json:"show_ids"`
+	TrackIds []int  `json:"track_ids"`
+	opts     printOptions
+}
+
+func (t TagListItemOutput) withOptions(o printOptions) PrettyPrinter {
+	t.opts = o
+	return t
 }
 
 func (t TagListItemOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
-	fmt.Fprintln(tw, "Name:\tDescription:\tGroup:")
-	fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Name, t.Description, t.Group)
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, t.opts.tagHeader(verbose))
+	fmt.Fprintln(tw, t.opts.tagRow(t, verbose))
 	fmt.Fprintln(tw)
 	showIDs := make([]string, 0, len(t.ShowIds))
 	for _, i := range t.ShowIds {
@@ -830,10 +2882,13 @@ type TrackTagOutput struct {
 
 type TrackTagsOutput struct {
 	Tags []TrackTagOutput
+	// term and context window notes and transcripts (search --context)
+	term    string
+	context int
 }
 
 func (t TrackTagsOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	tw := newTabWriter(w)
 	for _, tag := range t.Tags {
 		fmt.Fprintln(tw, "ID:\tTrackID:\tTagID:")
 		fmt.Fprintf(tw, "%d\t%d\t%d\n", tag.ID, tag.TrackID, tag.TagID)
@@ -841,20 +2896,44 @@ func (t TrackTagsOutput) PrettyPrint(w io.Writer, verbose bool) error {
 			fmt.Fprintln(tw)
 			fmt.Fprintln(tw, "Notes:")
 			notes := strings.ReplaceAll(tag.Notes, "&gt;", ">")
-			fmt.Fprintln(tw, notes)
+			fmt.Fprintln(tw, t.snippet(notes))
 		}
 		if tag.Transcript != "" {
 			if tag.Notes != "" {
 				fmt.Fprintln(tw)
 			}
 			fmt.Fprintln(tw, "Transcript:")
-			fmt.Fprintln(tw, tag.Transcript)
+			fmt.Fprintln(tw, t.snippet(tag.Transcript))
 		}
 		fmt.Fprintln(tw)
 	}
 	return tw.Flush()
 }
 
+// snippet returns text, or with a context set, just the context characters
+// either side of the first match of the term (ignoring case), with ... where
+// it was cut. Text without a match is cut after 2*context characters.
+func (t TrackTagsOutput) snippet(text string) string {
+	if t.context <= 0 {
+		return text
+	}
+	runes := []rune(text)
+	start, end := 0, min(len(runes), 2*t.context)
+	if loc := regexp.MustCompile("(?i)" + regexp.QuoteMeta(t.term)).FindStringIndex(text); t.term != "" && loc != nil {
+		from := utf8.RuneCountInString(text[:loc[0]])
+		to := from + utf8.RuneCountInString(text[loc[0]:loc[1]])
+		start, end = max(0, from-t.context), min(len(runes), to+t.context)
+	}
+	s := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		s = "..." + s
+	}
+	if end < len(runes) {
+		s += "..."
+	}
+	return s
+}
+
 func convertSearchToSearchOutput(s SearchResponse) SearchOutput {
 	o := SearchOutput{}
 	if s.Data.ExactShow.ID != 0 {
@@ -925,10 +3004,21 @@ type SearchOutput struct {
 		Tracks     []TrackOutput       `json:"tracks,omitempty"`
 		Venues     []VenueOutput       `json:"venues,omitempty"`
 	} `json:"results"`
+	opts printOptions
+	// term is what was searched for
+	term string
+}
+
+func (s SearchOutput) withOptions(o printOptions) PrettyPrinter {
+	s.opts = o
+	return s
 }
 
 func (s SearchOutput) PrettyPrint(w io.Writer, verbose bool) error {
-	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.DiscardEmptyColumns)
+	if s.opts.jsonl {
+		return s.printJSONL(w)
+	}
+	tw := newTabWriter(w)
 	var results bool
 	if s.Results.ExactShow != nil {
 		results = true
@@ -983,7 +3073,7 @@ func (s SearchOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	if len(s.Results.TrackTags) != 0 {
 		results = true
 		fmt.Fprintln(tw, "*** TRACK TAG RESULTS ***")
-		to := TrackTagsOutput{Tags: s.Results.TrackTags}
+		to := TrackTagsOutput{Tags: s.Results.TrackTags, term: s.term, context: s.opts.context}
 		if err := to.PrettyPrint(w, false); err != nil {
 			return err
 		}
@@ -1013,10 +3103,238 @@ func (s SearchOutput) PrettyPrint(w io.Writer, verbose bool) error {
 	return nil
 }
 
+// counts tallies the search results in each section, in the order the
+// sections are listed. Empty sections are left out.
+func (s SearchOutput) counts() SearchCountsOutput {
+	o := SearchCountsOutput{Counts: []SectionCount{}}
+	add := func(section string) {
+		if n := len(o.Counts); n != 0 && o.Counts[n-1].Section == section {
+			o.Counts[n-1].Count++
+			return
+		}
+		o.Counts = append(o.Counts, SectionCount{Section: section, Count: 1})
+	}
+	_ = s.each(func(section string, _ any) error {
+		add(section)
+		return nil
+	})
+	// each skips show tags, which aren't converted yet
+	for range s.Results.ShowTags {
+		add("show_tag")
+	}
+	return o
+}
+
+// SectionCount is the number of search results in a section, e.g. tracks.
+type SectionCount struct {
+	Section string `json:"section"`
+	Count   int    `json:"count"`
+}
+
+// SearchCountsOutput summarizes a search by section (search --compact).
+type SearchCountsOutput struct {
+	Counts []SectionCount `json:"counts"`
+}
+
+func (s SearchCountsOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	if len(s.Counts) == 0 {
+		_, err := fmt.Fprintln(w, "no results")
+		return err
+	}
+	parts := make([]string, 0, len(s.Counts))
+	for _, c := range s.Counts {
+		parts = append(parts, summarize(c.Count, strings.ReplaceAll(c.Section, "_", " "), nil))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(parts, ", "))
+	return err
+}
+
+// each calls fn with every search result and the section it's listed
+// under, stopping at the first error.
+func (s SearchOutput) each(fn func(section string, entity any) error) error {
+	if s.Results.ExactShow != nil {
+		if err := fn("exact_show", s.Results.ExactShow); err != nil {
+			return err
+		}
+	}
+	// show tags aren't converted yet, see convertSearchToSearchOutput
+	for _, show := range s.Results.OtherShows {
+		if err := fn("show", show); err != nil {
+			return err
+		}
+	}
+	for _, song := range s.Results.Songs {
+		if err := fn("song", song); err != nil {
+			return err
+		}
+	}
+	for _, tag := range s.Results.Tags {
+		if err := fn("tag", tag); err != nil {
+			return err
+		}
+	}
+	for _, tour := range s.Results.Tours {
+		if err := fn("tour", tour); err != nil {
+			return err
+		}
+	}
+	for _, tag := range s.Results.TrackTags {
+		if err := fn("track_tag", tag); err != nil {
+			return err
+		}
+	}
+	for _, track := range s.Results.Tracks {
+		if err := fn("track", track); err != nil {
+			return err
+		}
+	}
+	for _, venue := range s.Results.Venues {
+		if err := fn("venue", venue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagEntity converts entity to a map with key set to value, for listing
+// search results of different types together.
+func tagEntity(entity any, key, value string) (map[string]any, error) {
+	b, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %s to bytes: %w", value, err)
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("unable to flatten %s: %w", value, err)
+	}
+	m[key] = value
+	return m, nil
+}
+
+// printJSONL writes each search result as it's reached, one json object per
+// line with the section it came from.
+func (s SearchOutput) printJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return s.each(func(section string, entity any) error {
+		m, err := tagEntity(entity, "section", section)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("unable to write %s: %w", section, err)
+		}
+		return nil
+	})
+}
+
+// flattenSearch turns the search results into a single list, tagging each
+// entity with its type.
+func flattenSearch(s SearchOutput) (FlatSearchOutput, error) {
+	o := FlatSearchOutput{}
+	err := s.each(func(typ string, entity any) error {
+		m, err := tagEntity(entity, "type", typ)
+		if err != nil {
+			return err
+		}
+		o = append(o, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// FlatSearchOutput is every search result in one list, each with a "type".
+type FlatSearchOutput []map[string]any
+
+func (f FlatSearchOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "Type:\tResult:")
+	for _, entity := range f {
+		var result any
+		for _, key := range []string{"title", "name", "date", "notes"} {
+			if v, ok := entity[key]; ok && v != "" {
+				result = v
+				break
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%v\n", entity["type"], result)
+	}
+	return tw.Flush()
+}
+
+// manifestFile is the name of the manifest written alongside downloads.
+const manifestFile = "manifest.json"
+
+// Manifest records a downloaded show and the files written for it.
+type Manifest struct {
+	Show   ShowOutput      `json:"show"`
+	Tracks []ManifestTrack `json:"tracks"`
+}
+
+// ManifestTrack is a single downloaded track in a Manifest.
+type ManifestTrack struct {
+	FileName string `json:"file_name"`
+	Mp3      string `json:"mp3"`
+	Duration string `json:"duration"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// DownloadedFile describes a file written by DownloadTrack.
+type DownloadedFile struct {
+	Path   string
+	Size   int64
+	SHA256 string
+}
+
+const (
+	verifyOK       = "ok"
+	verifyMissing  = "missing"
+	verifySize     = "size mismatch"
+	verifyChecksum = "checksum mismatch"
+)
+
+// VerifyResult is the outcome of checking one file listed in a Manifest.
+type VerifyResult struct {
+	FileName string `json:"file_name"`
+	Status   string `json:"status"`
+}
+
+type VerifyOutput struct {
+	Dir     string         `json:"dir"`
+	Results []VerifyResult `json:"results"`
+}
+
+// Failures returns the number of files that didn't verify.
+func (v VerifyOutput) Failures() int {
+	var n int
+	for _, r := range v.Results {
+		if r.Status != verifyOK {
+			n++
+		}
+	}
+	return n
+}
+
+func (v VerifyOutput) PrettyPrint(w io.Writer, verbose bool) error {
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "File:\tStatus:")
+	for _, r := range v.Results {
+		fmt.Fprintf(tw, "%s\t%s\n", r.FileName, r.Status)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "%d of %d files in %s failed verification\n", v.Failures(), len(v.Results), v.Dir)
+	return tw.Flush()
+}
+
 type WriteCounter struct {
 	ContentLength int64
 	TotalWritten  int64
 	Name          string
+	// Output is where progress is printed, stdout when nil
+	Output io.Writer
 }
 
 func (wc *WriteCounter) Write(p []byte) (int, error) {
@@ -1027,8 +3345,101 @@ func (wc *WriteCounter) Write(p []byte) (int, error) {
 }
 
 func (wc *WriteCounter) PrintProgress() {
-	fmt.Printf("\r%s", strings.Repeat(" ", 70))
-	fmt.Printf("\rdownloaded %s of %s", humanizeBytes(wc.TotalWritten), wc.Name)
+	w := wc.Output
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, "\r%s", strings.Repeat(" ", 70))
+	fmt.Fprintf(w, "\rdownloaded %s of %s", humanizeBytes(wc.TotalWritten), wc.Name)
+}
+
+// rateLimiter is a token bucket shared by concurrent downloads, allowing
+// at most rate bytes per second with bursts of up to a second's worth.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	// swappable for testing
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	return &rateLimiter{
+		rate:   float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		now:    time.Now,
+		after:  time.After,
+	}
+}
+
+// wait takes n tokens from the bucket, waiting long enough to pay back any
+// shortfall unless ctx is done first.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.after(delay):
+		return nil
+	}
+}
+
+// rateLimitedReader reads from r as fast as limiter allows, giving up on a
+// wait once ctx is done.
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	// keep reads within the burst size so throttling stays smooth
+	if burst := int(r.limiter.rate); burst > 0 && len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if err := r.limiter.wait(r.ctx, n); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// parseRate converts a rate like 500k or 2m (per second, 1024-based) to
+// bytes per second.
+func parseRate(rate string) (int64, error) {
+	digits := rate
+	multiplier := int64(1)
+	switch strings.ToLower(rate[len(rate)-1:]) {
+	case "k":
+		multiplier = 1024
+		digits = rate[:len(rate)-1]
+	case "m":
+		multiplier = 1024 * 1024
+		digits = rate[:len(rate)-1]
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid rate %q: use bytes per second, optionally with a k or m suffix", rate)
+	}
+	return n * multiplier, nil
 }
 
 func humanizeBytes(b int64) string {
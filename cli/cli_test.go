@@ -1,7 +1,14 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestConvertMillisecondToConcertDuration(t *testing.T) {
@@ -27,3 +34,207 @@ func TestConvertMillisecondToConcertDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRate(t *testing.T) {
+	m := map[string]int64{
+		"100":  100,
+		"500k": 500 * 1024,
+		"2M":   2 * 1024 * 1024,
+	}
+	for rate, want := range m {
+		got, err := parseRate(rate)
+		if err != nil {
+			t.Errorf("%s: wanted nil, got %v", rate, err)
+		}
+		if got != want {
+			t.Errorf("%s: got %d want %d", rate, got, want)
+		}
+	}
+	for _, rate := range []string{"k", "fast", "0", "-5k"} {
+		if _, err := parseRate(rate); err == nil {
+			t.Errorf("%s: wanted error, got nil", rate)
+		}
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	// a fake clock that only moves when the limiter waits
+	now := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	var slept time.Duration
+	l := newRateLimiter(100)
+	l.now = func() time.Time { return now }
+	l.after = func(d time.Duration) <-chan time.Time {
+		slept += d
+		now = now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+	r := &rateLimitedReader{ctx: context.Background(), r: strings.NewReader(strings.Repeat("x", 1000)), limiter: l}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 1000 {
+		t.Fatalf("got %d bytes want 1000", len(b))
+	}
+	// the first second's worth is the burst, the other 900 bytes take 9s
+	want := 9 * time.Second
+	if slept < want-time.Millisecond || slept > want+time.Millisecond {
+		t.Errorf("slept %v want about %v", slept, want)
+	}
+
+	t.Run("a wait ends with its context", func(t *testing.T) {
+		l := newRateLimiter(100)
+		// the wait would never end on its own
+		l.after = func(time.Duration) <-chan time.Time { return nil }
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := &rateLimitedReader{ctx: ctx, r: strings.NewReader(strings.Repeat("x", 1000)), limiter: l}
+		if _, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestPrettyPrintNoTrailingWhitespace(t *testing.T) {
+	var show ShowResponse
+	readFixture(t, "../testdata/show.json", &show)
+	var track TrackResponse
+	readFixture(t, "../testdata/track.json", &track)
+	printers := map[string]PrettyPrinter{
+		"show":  convertShowToOutput(show.Data),
+		"track": convertTrackToOutput(track.Data),
+	}
+	for name, pp := range printers {
+		for _, verbose := range []bool{false, true} {
+			var sb strings.Builder
+			if err := pp.PrettyPrint(&sb, verbose); err != nil {
+				t.Fatal(err)
+			}
+			for i, line := range strings.Split(sb.String(), "\n") {
+				if line != strings.TrimRight(line, " \t") {
+					t.Errorf("%s (verbose %t) line %d has trailing whitespace: %q", name, verbose, i+1, line)
+				}
+			}
+		}
+	}
+}
+
+func TestTrimWriter(t *testing.T) {
+	var sb strings.Builder
+	tw := &trimWriter{w: &sb}
+	// spaces split across writes should still be trimmed (or kept)
+	for _, s := range []string{"a  ", " \n", "b ", " c", "  "} {
+		if _, err := io.WriteString(tw, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "a\nb  c"
+	if sb.String() != want {
+		t.Errorf("got %q want %q", sb.String(), want)
+	}
+}
+
+func readFixture(t *testing.T, path string, v any) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTagColor(t *testing.T) {
+	tags := TagsOutput{
+		Tags: []TagListItemOutput{
+			{Name: "SBD", Description: "Soundboard recording", Group: "Audio", Color: "#0a7cff"},
+			{Name: "Jamcharts", Description: "Jam Charts selections", Group: "Curated Selections", Color: "bogus"},
+		},
+	}
+	render := func(pp PrettyPrinter, verbose bool) string {
+		var sb strings.Builder
+		if err := pp.PrettyPrint(&sb, verbose); err != nil {
+			t.Fatal(err)
+		}
+		return sb.String()
+	}
+	plain := render(tags, true)
+	if !strings.Contains(plain, "#0a7cff") || strings.Contains(plain, "\x1b[") {
+		t.Errorf("without color, -v should list the hex, got %q", plain)
+	}
+	if strings.Contains(render(tags, false), "#0a7cff") {
+		t.Error("hex should only be listed with -v")
+	}
+	th := themes[defaultTheme]
+	colored := render(tags.withOptions(printOptions{theme: &th}), false)
+	if !strings.Contains(colored, "\x1b[38;2;010;124;255mSBD"+ansiReset) {
+		t.Errorf("wanted SBD tinted #0a7cff, got %q", colored)
+	}
+	// the columns should line up whatever the tint (or lack of one)
+	lines := strings.Split(strings.TrimSpace(colored), "\n")
+	col := strings.Index(lines[0], "Description:")
+	if strings.Index(lines[1], "Soundboard") != col || strings.Index(lines[2], "Jam Charts") != col {
+		t.Errorf("description column misaligned: %q", lines)
+	}
+}
+
+func TestASCIIWriter(t *testing.T) {
+	var sb strings.Builder
+	w := &asciiWriter{w: &sb}
+	b := []byte("Kraków — Straße ☃")
+	// a character split across writes should be held for the next one
+	for _, part := range [][]byte{b[:5], b[5:]} {
+		if _, err := w.Write(part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Krakow - Strasse ?"
+	if sb.String() != want {
+		t.Errorf("got %q want %q", sb.String(), want)
+	}
+}
+
+func TestHighlightWriter(t *testing.T) {
+	testCases := []struct {
+		name  string
+		color bool
+		want  string
+	}{
+		{"markers without color", false, "**David Bowie**\t**david bowie** reprise\nno match\n**DAVID BOWIE**"},
+		{"reverse video with color", true, ansiReverse + "David Bowie" + ansiReverseOff + "\t" + ansiReverse + "david bowie" + ansiReverseOff + " reprise\nno match\n" + ansiReverse + "DAVID BOWIE" + ansiReverseOff},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			w := newHighlightWriter(&sb, "david bowie", tc.color)
+			// a match split across writes is still marked
+			for _, part := range []string{"David Bo", "wie\tdavid bowie reprise\nno match\n", "DAVID BOWIE"} {
+				if _, err := io.WriteString(w, part); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tc.want {
+				t.Errorf("got %q want %q", sb.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestHighlightWriterSkipsColorCodes(t *testing.T) {
+	var sb strings.Builder
+	w := newHighlightWriter(&sb, "3m", true)
+	themed := ansiCyan + "1993-04-09" + ansiReset + "\t" + ansiYellow + "13m 31s" + ansiReset + "\n"
+	if _, err := io.WriteString(w, themed); err != nil {
+		t.Fatal(err)
+	}
+	want := ansiCyan + "1993-04-09" + ansiReset + "\t" + ansiYellow + "1" + ansiReverse + "3m" + ansiReverseOff + " 31s" + ansiReset + "\n"
+	if sb.String() != want {
+		t.Errorf("got %q want %q", sb.String(), want)
+	}
+}
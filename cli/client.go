@@ -1,51 +1,278 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
+// defaultParallel is the default number of concurrent requests
+// (downloads or detail fetches) a Client will make.
+const defaultParallel = 4
+
+// defaultRetryBudget is the default number of retries shared by all of a
+// command's requests, so one flaky endpoint can't stall a bulk fetch.
+const defaultRetryBudget = 10
+
+// groupByVenue groups a shows list under each show's venue (--group-by).
+const groupByVenue = "venue"
+
+// defaultRetryWait is the backoff before the first retry of a request. It
+// doubles with each retry after that.
+const defaultRetryWait = 500 * time.Millisecond
+
+// ErrNotFound is returned (wrapped) when the server responds with a 404.
+var ErrNotFound = errors.New("not found")
+
+// ErrUnauthorized is returned (wrapped) when the server rejects the api key
+// for a request, with a 401 or 403.
+var ErrUnauthorized = errors.New("not authorized, check your api key")
+
+// ErrNotCached is returned (wrapped) in offline mode for a response that
+// wasn't saved with --record.
+var ErrNotCached = errors.New("not cached")
+
+// ErrUnknownField is returned (wrapped) with --strict-json for a response
+// carrying a field the client doesn't model.
+var ErrUnknownField = errors.New("unknown api field")
+
 type Client struct {
 	HTTPClient *http.Client
 	ErrGroup   *errgroup.Group
 	BaseURL    string
 	APIKey     string
 	PrintJSON  bool
+	PrintJSONL bool
+	// PrintTSV prints lists as tab-separated rows with a header
+	PrintTSV   bool
 	Query      string
 	Parameters []string
+	// Args holds any positional arguments following the command.
+	Args       []string
 	Output     io.Writer
+	ErrOutput  io.Writer
 	Verbose    bool
 	Debug      bool
+	Trace      bool
 	Download   bool
 	RawOutput  bool
+	NestedSets bool
+	Flatten    bool
+	Summary    bool
+	Links      bool
+	HeadOnly   bool
+	Segues     bool
+	Count      bool
+	SBD        bool
+	All        bool
+	Expand     bool
+	Parallel   int
+	Since      time.Time
+	Manifest   bool
+	// DownloadDir is the directory downloads are written to.
+	DownloadDir string
+	// ByYear nests show downloads under a directory for their year, e.g.
+	// 1997/1997-11-22
+	ByYear     bool
+	JSONIndent string
+	ByArtist   bool
+	Set        string
+	// Columns orders the verbose shows table, e.g. date,venue,id
+	Columns []string
+	// PrefetchVenue replaces a show's partial venue with the full venue details
+	PrefetchVenue bool
+	// DurationSeconds prints show and track durations as whole seconds
+	DurationSeconds bool
+	// DurationClock prints show and track durations as H:MM:SS
+	DurationClock bool
+	// ASCII transliterates output to ASCII for terminals without UTF-8
+	ASCII bool
+	// Cover downloads a show's cover art
+	Cover bool
+	// MergeSets prints a show's tracks as one numbered list
+	MergeSets bool
+	// RecordDir, when set, is where api responses are saved as fixtures
+	RecordDir string
+	// Offline serves responses from RecordDir instead of the network
+	Offline bool
+	// Pager pipes text output through PAGER when writing to a terminal
+	Pager bool
+	// VenuesBy groups the venues list by city, state, or country
+	VenuesBy string
+	// JSONRoot, when set, is the top-level key json output is nested under
+	JSONRoot string
+	// Compact prints a search as a count of results per section
+	Compact bool
+	// NoTracks drops the track results from a search
+	NoTracks bool
+	// ContextChars trims search track tag notes and transcripts to this many
+	// characters around the search term (0 prints them whole)
+	ContextChars int
+	// MarkTours marks where each tour starts in the shows list
+	MarkTours bool
+	// Highlight marks each match of a term in text output
+	Highlight string
+	// IncludeEmpty prints empty sections, e.g. Show Dates: (none)
+	IncludeEmpty bool
+	// NoAPIKey sends requests without an api key, for endpoints that allow
+	// anonymous access
+	NoAPIKey bool
+	// Watch re-fetches the most recent show (shows --last) this often,
+	// printing it whenever it changes
+	Watch time.Duration
+	// Breakdown counts the listed songs (every song with All) as originals
+	// and covers, with the most covered artists
+	Breakdown bool
+	// MinLikes drops listed shows and tracks with fewer likes
+	MinLikes int
+	// MinTracks drops listed shows with fewer tracks. Shows listed without
+	// their tracks are kept, since there's nothing to count
+	MinTracks int
+	// WithShowContext places each listed track in its show, fetching the
+	// shows
+	WithShowContext bool
+	// JSONIDStrings writes ids as strings in json output
+	JSONIDStrings bool
+	// Adjacent swaps the show on a date for the next (1) or previous (-1)
+	// show, 0 for neither
+	Adjacent int
+	// EraCounts totals the eras list's shows from the years list
+	EraCounts bool
+	// VenueSongs lists the songs played across a venue's shows instead of
+	// the venue's details
+	VenueSongs bool
+	// Limit caps how many of a venue's shows VenueSongs fetches, 0 for all
+	Limit int
+	// Against is the tour tour-diff compares against
+	Against string
+	// StrictJSON fails a request whose response has fields the client
+	// doesn't model, to catch api drift
+	StrictJSON bool
+	// SetlistNotation prints a single show as a compact setlist, e.g.
+	// "Set 1: Possum, Ya Mar > Bowie"
+	SetlistNotation bool
+	// GroupBy groups a shows list (only by venue for now)
+	GroupBy string
+	// Share prints the phish.in page for a show, track, song, venue, or tour
+	// instead of its details
+	Share bool
+	// SortTracks orders a show's tracks by duration or title, listing them
+	// as one list (SortTracksDesc reverses it)
+	SortTracks     string
+	SortTracksDesc bool
+	// MaxTracks caps the tracks printed for a show or song (0 prints them all)
+	MaxTracks int
+	// TrackPage and TrackPerPage page through a song's tracks (0 lists them all)
+	TrackPage    int
+	TrackPerPage int
+	// Dedupe collapses listed venues that are the same place under another name
+	Dedupe bool
+	// NormalizeDates lists each year of a span like 1983-1987 on its own
+	NormalizeDates bool
+	// Retries is how many times a request failing with a network error,
+	// 429, or 5xx is retried
+	Retries int
+	// RetryBudget caps the retries across all of a command's requests, 0
+	// for no cap
+	RetryBudget int
+	// RetryNonIdempotent lets Retries apply to writes like Post too
+	RetryNonIdempotent bool
+	// requests caps the requests in flight at Parallel across all of a
+	// command's work, however deeply it's nested
+	requests *requestLimit
+	// retriesUsed counts the retries taken against RetryBudget
+	retriesUsed int32
+	// retryWait is the backoff before a request's first retry
+	retryWait time.Duration
+	// SkipExisting skips downloading files that are already complete
+	SkipExisting bool
+	// DownloadSummary swaps each file's progress for one summary of the
+	// run's downloads once they finish
+	DownloadSummary bool
+	// downloads tallies the run's downloads for DownloadSummary
+	downloads downloadStats
+	// ExcludeTags drops shows and tracks carrying any of these tags
+	ExcludeTags []string
+	// MetricsFile, when set, is where a run's metrics are written
+	MetricsFile string
+	// stats counts a run's requests for MetricsFile
+	stats runStats
+	// theme colors text output, nil when color is off
+	theme *theme
+	// limiter throttles downloads when set (--limit-rate)
+	limiter *rateLimiter
+	// Now returns the current time, and is swappable for testing.
+	Now func() time.Time
+	// After waits out a duration like time.After (its default), and is
+	// swappable for testing.
+	After func(time.Duration) <-chan time.Time
+	// Logger records diagnostics (requests, warnings). When nil, warnings
+	// (and with Debug, requests) are logged to ErrOutput.
+	Logger *slog.Logger
+	// Rand picks constrained random shows, and is seedable for testing.
+	Rand *rand.Rand
+	// RandomYear and RandomEra limit random-show to a year or era.
+	RandomYear string
+	RandomEra  string
+	// YearFrom and YearTo limit the years list to a range, 0 for no bound.
+	YearFrom int
+	YearTo   int
 }
 
 func NewClient(apiKey string, output io.Writer) *Client {
 	return &Client{
-		HTTPClient: http.DefaultClient,
-		BaseURL:    "https://phish.in/api/v1",
-		APIKey:     apiKey,
-		Output:     output,
-		ErrGroup:   &errgroup.Group{},
+		HTTPClient:  http.DefaultClient,
+		BaseURL:     "https://phish.in/api/v1",
+		APIKey:      apiKey,
+		Output:      output,
+		ErrOutput:   os.Stderr,
+		ErrGroup:    &errgroup.Group{},
+		Parallel:    defaultParallel,
+		Now:         time.Now,
+		After:       time.After,
+		Rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		DownloadDir: ".",
+		JSONIndent:  defaultJSONIndent,
+		RetryBudget: defaultRetryBudget,
+		retryWait:   defaultRetryWait,
+		requests:    &requestLimit{},
 	}
 }
 
 func (c *Client) FormatURL(path string) string {
+	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
 	if c.Query != "" {
-		// return now to avoid mixing in params
-		return fmt.Sprintf("%s/%s/%s", c.BaseURL, path, c.Query)
+		url = fmt.Sprintf("%s/%s", url, c.Query)
+		if !combinesQueryAndParams(path) {
+			// return now to avoid mixing in params
+			return url
+		}
 	}
-	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
 	if len(c.Parameters) != 0 {
 		params := strings.Join(c.Parameters, "&")
 		url = fmt.Sprintf("%s?%s", url, params)
@@ -57,8 +284,12 @@ func (c *Client) fromArgs(args []string) error {
 	phishin := flag.NewFlagSet("phishin", flag.ExitOnError)
 	query := phishin.String("search", "", "search query")
 	phishin.StringVar(query, "s", "", "search query")
-	output := phishin.String("output", "text", "print output as <text> or <json>")
-	phishin.StringVar(output, "o", "text", "print output as <text> or <json>")
+	output := phishin.String("output", "text", "print output as <text>, <json>, <jsonl> (search), or <tsv> (lists)")
+	phishin.StringVar(output, "o", "text", "print output as <text>, <json>, <jsonl> (search), or <tsv> (lists)")
+	jsonIndent := phishin.String("json-indent", "2", "indent json output with <n> spaces or <tab>")
+	withShowContext := phishin.Bool("with-show-context", false, "for tracks, add each track's place in its set and its show's track count")
+	jsonIDStrings := phishin.Bool("json-numbers-as-strings", false, "write ids as strings in json output, for consumers that round big numbers")
+	jsonRoot := phishin.String("json-root", "", "nest json output under the top-level key <name>")
 	sortDir := phishin.String("sort-dir", "", "sort results <asc> or <desc>")
 	phishin.StringVar(sortDir, "dir", "", "sort results <asc> or <desc>")
 	sortAttr := phishin.String("sort-attr", "", "sort results <attr>")
@@ -71,30 +302,386 @@ func (c *Client) fromArgs(args []string) error {
 	phishin.StringVar(tag, "t", "", "filter by <tag>")
 	verbose := phishin.Bool("verbose", false, "verbose output")
 	phishin.BoolVar(verbose, "v", false, "verbose output")
-	debug := phishin.Bool("debug", false, "print the url that the client is sending to the server")
+	apiKey := phishin.String("api-key", "", "phishin api key (overrides --api-key-file and PHISHIN_API_KEY)")
+	apiKeyFile := phishin.String("api-key-file", "", "read the phishin api key from <file> (overrides PHISHIN_API_KEY)")
+	noAPIKey := phishin.Bool("no-api-key", false, "send requests without an api key (the server decides if that's ok)")
+	baseURL := phishin.String("base-url", "", "send requests to <url> instead of https://phish.in/api/v1")
+	insecure := phishin.Bool("insecure", false, "skip tls verification (only with --base-url)")
+	proxy := phishin.String("proxy", "", "send requests through the proxy at <url> (http, https, or socks5)")
+	debug := phishin.Bool("debug", false, "log the url that the client is sending to the server")
+	logLevel := phishin.String("log-level", "", "minimum level to log: debug, info, warn, or error (default warn)")
+	trace := phishin.Bool("trace", false, "log request and response headers (authorization redacted) to stderr")
 	download := phishin.Bool("d", false, "download (if applicable)")
+	outputDir := phishin.String("output-dir", "", "write downloads under <dir> instead of the current directory")
+	byYear := phishin.Bool("by-year", false, "with -d, save shows and tracks under <year>/<date>")
+	retries := phishin.Int("retries", 0, "retry a request failing with a network error, 429, or 5xx up to <n> times, downloads included")
+	retryNonIdempotent := phishin.Bool("retry-non-idempotent", false, "let --retries resend writes (likes), which may land twice")
+	retryBudget := phishin.Int("retry-budget", defaultRetryBudget, "max retries across all of a command's requests (0 for no cap)")
+	limitRate := phishin.String("limit-rate", "", "cap total download speed at <rate> bytes per second (e.g. 500k, 2m)")
+	set := phishin.String("set", "", "only include (and download) tracks from <set>, e.g. \"Set 2\" or encore")
+	downloadSummary := phishin.Bool("download-summary", false, "with -d, print one summary when downloads finish instead of each file's progress")
+	skipExisting := phishin.Bool("skip-existing", false, "with -d, skip files already downloaded in full (checked against the manifest, or the server's size)")
+	manifest := phishin.Bool("manifest", false, "write a manifest.json alongside downloaded show files")
+	color := phishin.String("color", "auto", "color text output: auto, always, or never")
+	themeName := phishin.String("theme", os.Getenv("PHISHIN_THEME"), "color theme for text output: "+strings.Join(themeNames(), ", "))
 	raw := phishin.Bool("raw", false, "print full api json response")
 	phishin.BoolVar(raw, "r", false, "print full api json response")
+	year := phishin.String("year", "", "pick a random show from <year> (random-show)")
+	era := phishin.String("era", "", "pick a random show from <era>, e.g. 3.0 (random-show)")
+	from := phishin.Int("from", 0, "list years from <year> on (years)")
+	to := phishin.Int("to", 0, "list years up to <year> (years)")
+	seed := phishin.Int64("seed", 0, "seed for random-show --year/--era, for a repeatable pick")
+	columns := phishin.String("columns", "", "comma-separated columns (and their order) for the verbose shows table")
+	var excludeTags []string
+	phishin.Func("exclude-tag", "drop shows and tracks tagged <tag> (repeatable, or comma-separated)", func(v string) error {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				excludeTags = append(excludeTags, tag)
+			}
+		}
+		return nil
+	})
+	var params []string
+	phishin.Func("param", "add <key=value> to the request's query parameters (repeatable)", func(v string) error {
+		params = append(params, v)
+		return nil
+	})
+	sbd := phishin.Bool("sbd", false, "only include soundboard recordings")
+	count := phishin.Bool("count", false, "print only the total number of results for a list")
+	share := phishin.Bool("share", false, "print the phish.in page for a show, track, song, venue, or tour")
+	segues := phishin.Bool("segues", false, "join segued tracks with > in a show's setlist")
+	pager := phishin.Bool("pager", false, "page text output through $PAGER (or less) when writing to a terminal")
+	strictJSON := phishin.Bool("strict-json", false, "fail on api response fields the client doesn't know about")
+	offline := phishin.Bool("offline", false, "only read responses saved with --record, never the network")
+	metricsFile := phishin.String("metrics-file", "", "write request, error, and download metrics to <file> (prometheus text format) after the run")
+	record := phishin.String("record", "", "save each api response to <dir> as a test fixture")
+	sortTracks := phishin.String("sort-tracks", "", "order a show's tracks by <duration> or <title>, optionally with :asc or :desc")
+	mergeSets := phishin.Bool("merge-sets", false, "list a show's tracks as one numbered list, without set headers")
+	cover := phishin.Bool("cover", false, "download a show's cover art")
+	ascii := phishin.Bool("ascii", false, "transliterate output to ascii, e.g. Montréal to Montreal")
+	durationFormat := phishin.String("duration-format", "human", "print durations as <human> (e.g. 13m 31s), <seconds>, or <clock> (0:13:31)")
+	prefetchVenue := phishin.Bool("prefetch-venue", false, "fetch the full venue details for a show")
+	headOnly := phishin.Bool("head-only", false, "skip track details when listing shows")
+	links := phishin.Bool("links", false, "include the phish.in page for each show in text output")
+	summary := phishin.Bool("summary", false, "print a one-line summary above list output")
+	all := phishin.Bool("all", false, "scan every show (report incomplete)")
+	nestedSets := phishin.Bool("nested-sets", false, "group show tracks by set in json output")
+	flatten := phishin.Bool("flatten", false, "list search results as one array, each tagged with its type, in json output")
+	watch := phishin.Duration("watch", 0, "with shows --last, check for a new show every <interval> (e.g. 5m)")
+	breakdown := phishin.Bool("breakdown", false, "for songs, count originals and covers, and the most covered artists")
+	venueSongs := phishin.Bool("songs", false, "for venue details, list the songs played across the venue's shows")
+	limit := phishin.Int("limit", 0, "with venues --songs, only fetch the venue's first <n> shows (0 for all)")
+	against := phishin.String("against", "", "for tour-diff, the tour (slug or id) to compare against")
+	minTracks := phishin.Int("min-tracks", 0, "only include shows with at least <n> tracks")
+	setlistNotation := phishin.Bool("setlist-notation", false, "print a show's setlist the way fans share it, e.g. Set 1: Possum, Ya Mar > Bowie")
+	minLikes := phishin.Int("min-likes", 0, "only include shows and tracks with at least <n> likes")
+	groupBy := phishin.String("group-by", "", "group a shows list by <venue>")
+	byArtist := phishin.Bool("by-artist", false, "group songs by their original artist")
+	next := phishin.Bool("next", false, "for shows -s <date>, get the next show after that date")
+	prev := phishin.Bool("prev", false, "for shows -s <date>, get the last show before that date")
+	eraCounts := phishin.Bool("counts", false, "for eras, total each era's shows")
+	normalizeDates := phishin.Bool("normalize-dates", false, "list each year in the 1983-1987 span on its own (eras, years)")
+	maxTracks := phishin.Int("max-tracks", 0, "print at most <n> of a show's or song's tracks")
+	trackPage := phishin.Int("track-page", 0, "for song details, which page of its tracks to list")
+	trackPerPage := phishin.Int("track-per-page", 0, "for song details, number of its tracks to list per page")
+	song := phishin.String("song", "", "for tracks, only list performances of the song with <slug>")
+	first := phishin.Bool("first", false, "get the earliest show (shows)")
+	last := phishin.Bool("last", false, "get the most recent show (shows)")
+	compact := phishin.Bool("compact", false, "for search, print just the number of results in each section")
+	contextChars := phishin.Int("context", 0, "for search, print <n> characters of track tag notes and transcripts around the term")
+	noTracks := phishin.Bool("no-tracks", false, "for search, leave out the track results")
+	markTours := phishin.Bool("mark-tours", false, "for shows lists, mark where each tour starts with a line naming it")
+	includeEmpty := phishin.Bool("include-empty", false, "print sections even when they're empty")
+	highlight := phishin.String("highlight", "", "mark each (case-insensitive) match of <term> in text output")
+	dedupe := phishin.Bool("dedupe", false, "for venues, collapse venues listed under more than one name at a location")
+	byCity := phishin.Bool("by-city", false, "total the listed venues' shows by city")
+	byState := phishin.Bool("by-state", false, "total the listed venues' shows by state")
+	byCountry := phishin.Bool("by-country", false, "total the listed venues' shows by country")
+	expand := phishin.Bool("expand", false, "fetch details for each show a tag appears in (or, with -v, each show of a tour)")
+	parallel := phishin.Int("parallel", defaultParallel, "max number of concurrent requests")
+	since := phishin.String("since", "", "only include results updated since <yyyy-mm-dd> or <7d/24h/2w> ago")
 
 	phishin.Usage = func() {
 		fmt.Fprint(os.Stderr, usage)
 		fmt.Println("Flags:")
 		phishin.PrintDefaults()
 	}
-	if err := phishin.Parse(args[1:]); err != nil {
+	// some commands take positional arguments, which may come before the flags
+	rest := args[1:]
+	var positional []string
+	for len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		positional = append(positional, rest[0])
+		rest = rest[1:]
+	}
+	if err := phishin.Parse(rest); err != nil {
 		return fmt.Errorf("error parsing args: %w", err)
 	}
+	c.Args = append(positional, phishin.Args()...)
+
+	// precedence is flag > file > env, the latter set by NewClient
+	if *apiKeyFile != "" {
+		b, err := os.ReadFile(*apiKeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to read api key file: %w", err)
+		}
+		c.APIKey = strings.TrimSpace(string(b))
+	}
+	if *apiKey != "" {
+		c.APIKey = *apiKey
+	}
+	c.NoAPIKey = *noAPIKey
+	if c.NoAPIKey {
+		if *apiKey != "" || *apiKeyFile != "" {
+			return errors.New("pick one of no-api-key or an api key")
+		}
+		c.APIKey = ""
+	}
+
+	if *logLevel != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+			return fmt.Errorf("invalid log level %q, options are debug, info, warn, or error", *logLevel)
+		}
+		c.Logger = newLogger(c.ErrOutput, level)
+	}
+	if *baseURL != "" {
+		c.BaseURL = strings.TrimSuffix(*baseURL, "/")
+	}
+	if *insecure {
+		if *baseURL == "" {
+			return errors.New("insecure is only supported alongside --base-url")
+		}
+		c.logger().Warn("tls verification is disabled, responses could be tampered with", "base_url", c.BaseURL)
+		c.transport().TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if *proxy != "" {
+		if err := c.setProxy(*proxy); err != nil {
+			return err
+		}
+	}
 
 	c.Query = *query
 	c.PrintJSON = *output == "json"
+	c.PrintJSONL = *output == "jsonl"
+	c.PrintTSV = *output == "tsv"
+	indent, err := parseJSONIndent(*jsonIndent)
+	if err != nil {
+		return err
+	}
+	c.JSONIndent = indent
+	c.JSONRoot = *jsonRoot
+	c.JSONIDStrings = *jsonIDStrings
+	if c.JSONIDStrings && !c.PrintJSON {
+		return errors.New("json-numbers-as-strings is only supported with -o json")
+	}
 	c.Verbose = *verbose
 	c.Debug = *debug
+	c.Trace = *trace
 	c.Download = *download
+	if *outputDir != "" {
+		c.DownloadDir = *outputDir
+	}
+	c.ByYear = *byYear
+	if *manifest && !*download {
+		return errors.New("manifest requires downloading (-d)")
+	}
+	c.Manifest = *manifest
+	c.SkipExisting = *skipExisting
+	if *downloadSummary && !*download {
+		return errors.New("download-summary requires downloading (-d)")
+	}
+	c.DownloadSummary = *downloadSummary
+	c.Set = *set
+	if *limitRate != "" {
+		rate, err := parseRate(*limitRate)
+		if err != nil {
+			return err
+		}
+		c.limiter = newRateLimiter(rate)
+	}
+	if err := c.setTheme(*color, *themeName); err != nil {
+		return err
+	}
 	c.RawOutput = *raw
+	c.NestedSets = *nestedSets
+	c.All = *all
+	c.Summary = *summary
+	c.Links = *links
+	c.HeadOnly = *headOnly
+	c.PrefetchVenue = *prefetchVenue
+	c.ASCII = *ascii
+	c.Cover = *cover
+	c.SortTracks, c.SortTracksDesc = "", false
+	if *sortTracks != "" {
+		attr, desc, err := parseTrackSort(*sortTracks)
+		if err != nil {
+			return err
+		}
+		if *nestedSets {
+			return errors.New("sort-tracks lists tracks without their sets, so it can't be used with nested-sets")
+		}
+		c.SortTracks, c.SortTracksDesc = attr, desc
+	}
+	// sorted tracks don't keep to their sets
+	c.MergeSets = *mergeSets || c.SortTracks != ""
+	c.RecordDir = *record
+	c.MetricsFile = *metricsFile
+	c.Offline = *offline
+	c.StrictJSON = *strictJSON
+	c.Pager = *pager
+	if c.Offline && c.RecordDir == "" {
+		return errors.New("offline needs --record <dir> to read saved responses from")
+	}
+	if c.Offline && c.Download {
+		return errors.New("downloads aren't recorded, so -d can't be used with --offline")
+	}
+	switch *durationFormat {
+	case "human", "seconds", "clock":
+		c.DurationSeconds = *durationFormat == "seconds"
+		c.DurationClock = *durationFormat == "clock"
+	default:
+		return fmt.Errorf("invalid duration format %q, options are human, seconds, or clock", *durationFormat)
+	}
+	c.Segues = *segues
+	c.Columns = nil
+	if *columns != "" {
+		cols, err := parseColumns(*columns)
+		if err != nil {
+			return err
+		}
+		c.Columns = cols
+	}
+	c.Flatten = *flatten
+	c.Expand = *expand
+	c.ByArtist = *byArtist
+	c.NormalizeDates = *normalizeDates
+	c.EraCounts = *eraCounts
+	c.Dedupe = *dedupe
+	c.Highlight = *highlight
+	c.IncludeEmpty = *includeEmpty
+	c.MarkTours = *markTours
+	c.Compact = *compact
+	c.NoTracks = *noTracks
+	c.VenuesBy = ""
+	for by, set := range map[string]bool{"city": *byCity, "state": *byState, "country": *byCountry} {
+		if !set {
+			continue
+		}
+		if c.VenuesBy != "" {
+			return errors.New("group venues by one of --by-city, --by-state, or --by-country")
+		}
+		c.VenuesBy = by
+	}
+	if *parallel < 1 {
+		return errors.New("parallel must be at least 1")
+	}
+	c.Parallel = *parallel
+	if *retries < 0 || *retryBudget < 0 {
+		return errors.New("retries and retry-budget can't be negative")
+	}
+	c.Retries = *retries
+	c.RetryBudget = *retryBudget
+	c.RetryNonIdempotent = *retryNonIdempotent
+	if *since != "" {
+		t, err := parseSince(*since, c.Now())
+		if err != nil {
+			return err
+		}
+		c.Since = t
+	}
 
 	path := args[0]
+	c.Count = *count
+	if c.PrintJSONL && path != searchPath {
+		return errors.New("jsonl output is only supported for search")
+	}
+	if c.PrintTSV && !c.isList(path) && (path != yearsPath || c.Query != "") {
+		return errors.New("tsv output is only supported for lists (shows, songs, venues, tracks, tours, tags, years, shows-on-day-of-year)")
+	}
+	if c.Count && !c.isList(path) {
+		return errors.New("count is only supported for lists (shows, songs, venues, tracks, tours, tags, shows-on-day-of-year)")
+	}
+	if *contextChars < 0 {
+		return errors.New("context can't be negative")
+	}
+	if *contextChars != 0 && path != searchPath {
+		return errors.New("context is only supported for search")
+	}
+	c.ContextChars = *contextChars
+	c.Watch = *watch
+	if c.Watch != 0 {
+		if path != showsPath || !*last || c.Query != "" {
+			return errors.New("watch is only supported for shows --last")
+		}
+		if c.Watch < 0 {
+			return errors.New("watch can't be negative")
+		}
+	}
+	c.Breakdown = *breakdown
+	if c.Breakdown && (path != songsPath || c.Query != "") {
+		return errors.New("breakdown is only supported for the songs list")
+	}
+	c.SetlistNotation = *setlistNotation
+	if c.SetlistNotation && !(path == showsPath && c.Query != "" || path == showOnDatePath || path == randomShowPath) {
+		return errors.New("setlist-notation is only supported for a single show")
+	}
+	c.GroupBy = *groupBy
+	if c.GroupBy != "" {
+		if c.GroupBy != groupByVenue {
+			return fmt.Errorf("invalid group-by %q, options are venue", c.GroupBy)
+		}
+		if (path != showsPath && path != showsDayOfYearPath) || (path == showsPath && c.Query != "") {
+			return errors.New("group-by is only supported for shows lists")
+		}
+	}
+	if c.PrintTSV && (c.GroupBy != "" || c.ByArtist || c.Breakdown || c.Count || c.VenuesBy != "") {
+		// these print a summary of the list rather than its rows
+		return errors.New("tsv output isn't supported with group-by, by-artist, breakdown, count, or by-city/state/country")
+	}
+	c.Share = *share
+	if c.Share && (!shareable(path) || c.Query == "") {
+		return errors.New("share needs a show, track, song, venue, or tour (e.g. shows -s 1997-11-22 --share)")
+	}
+	c.SBD = *sbd
+	c.ExcludeTags = excludeTags
+	if *minLikes < 0 {
+		return errors.New("min-likes can't be negative")
+	}
+	c.MinLikes = *minLikes
+	if *minTracks < 0 {
+		return errors.New("min-tracks can't be negative")
+	}
+	c.MinTracks = *minTracks
+	if c.MinTracks > 0 && (path != showsPath && path != showsDayOfYearPath || c.Query != "") {
+		return errors.New("min-tracks is only supported for the shows list")
+	}
+	c.YearFrom = *from
+	c.YearTo = *to
 	switch path {
 	case showsPath, tracksPath:
+		// these accept the sbd tag server-side, with the client-side
+		// filter catching anything else (e.g. when --tag picks another)
+		if c.SBD && *tag == "" {
+			*tag = sbdTag
+		}
+		if *first || *last {
+			if path != showsPath || c.Query != "" {
+				return errors.New("first and last are only supported for the shows list")
+			}
+			if *first && *last {
+				return errors.New("pick one of first or last")
+			}
+			*sortDir, *sortAttr, *perPage, *page = "desc", "date", 1, 1
+			if *first {
+				*sortDir = "asc"
+			}
+		}
+		if *song != "" {
+			if path != tracksPath || c.Query != "" {
+				return errors.New("song is only supported for the tracks list")
+			}
+			c.Parameters = append(c.Parameters, "song_slug="+url.QueryEscape(*song))
+		}
 		c.parseTag(*tag)
 		c.parsePageParams(*perPage, *page)
 		c.parseSortParams(*sortDir, *sortAttr)
@@ -104,6 +691,9 @@ func (c *Client) fromArgs(args []string) error {
 	case yearsPath:
 		// let's always include this
 		c.Parameters = append(c.Parameters, "include_show_counts=true")
+		if *from != 0 && *to != 0 && *from > *to {
+			return fmt.Errorf("--from %d is after --to %d", *from, *to)
+		}
 	case showOnDatePath:
 		if c.Query == "" {
 			return errors.New("need a date")
@@ -115,20 +705,232 @@ func (c *Client) fromArgs(args []string) error {
 	case randomShowPath:
 		// doesn't take a parameter, so drop if user added one
 		c.Query = ""
+		if *year != "" && *era != "" {
+			return errors.New("random-show takes a year or an era, not both")
+		}
+		c.RandomYear = *year
+		c.RandomEra = *era
+		if *seed != 0 {
+			c.Rand = rand.New(rand.NewSource(*seed))
+		}
 	case searchPath:
 		if c.Query == "" {
 			return errors.New("need a search term")
 		}
+		c.parsePageParams(*perPage, *page)
+	case authCheckPath:
+		if c.NoAPIKey {
+			return errors.New("auth-check needs an api key to check")
+		}
+	case auditPath:
+		if len(c.Args) == 0 || c.Args[0] != toursPath {
+			return errors.New("audit needs something to audit (supported: tours)")
+		}
+	case likePath:
+		if len(c.Args) != 2 || (c.Args[0] != showsPath && c.Args[0] != tracksPath) {
+			return errors.New("usage: like <shows|tracks> <id>")
+		}
+		if _, err := strconv.Atoi(c.Args[1]); err != nil {
+			return fmt.Errorf("like needs a %s id, got %q", strings.TrimSuffix(c.Args[0], "s"), c.Args[1])
+		}
+	case tourDiffPath:
+		if len(c.Args) != 1 || *against == "" {
+			return errors.New("usage: tour-diff <tour> --against <tour>")
+		}
+		c.Against = *against
+	case resolvePath:
+		if len(c.Args) != 2 || !resolvable(c.Args[0]) {
+			return errors.New("usage: resolve <songs|tours|venues|tags> <slug or id>")
+		}
+	case reportPath:
+		if len(c.Args) == 0 || c.Args[0] != "incomplete" {
+			return errors.New("report needs something to report on (supported: incomplete)")
+		}
+		if len(c.Args) < 2 && !c.All {
+			return errors.New("report incomplete needs a year (e.g. 1994) or --all")
+		}
 	case erasPath, toursPath, tagsPath:
 		// do nothing
 	default:
 		fmt.Fprintf(os.Stderr, "%s is not a recognized command\n", path)
 		return errors.New(endpointList)
 	}
+	// count is the api's total, which the client-side filters don't change.
+	// sbd is only filtered client-side when it isn't sent as the tag
+	sbdClientSide := c.SBD && !((path == showsPath || path == tracksPath) && *tag == sbdTag)
+	if c.Count && (!c.Since.IsZero() || sbdClientSide || len(c.ExcludeTags) != 0 || c.MinLikes != 0 || c.MinTracks != 0) {
+		return errors.New("count isn't supported with the client-side filters (since, sbd with another tag, exclude-tag, min-likes, min-tracks)")
+	}
+	if *trackPage != 0 || *trackPerPage != 0 {
+		if path != songsPath || c.Query == "" {
+			return errors.New("track-page and track-per-page are only supported for song details")
+		}
+		if *trackPage < 0 || *trackPerPage < 0 {
+			return errors.New("track-page and track-per-page can't be negative")
+		}
+	}
+	c.TrackPage, c.TrackPerPage = *trackPage, *trackPerPage
+	c.Adjacent = 0
+	if *next || *prev {
+		if *next && *prev {
+			return errors.New("pick one of next or prev")
+		}
+		if _, err := time.Parse("2006-01-02", c.Query); path != showsPath || err != nil {
+			return errors.New("next and prev need a show date (e.g. shows -s 1994-10-31 --next)")
+		}
+		c.Adjacent = 1
+		if *prev {
+			c.Adjacent = -1
+		}
+	}
+	c.WithShowContext = *withShowContext
+	if c.WithShowContext && (path != tracksPath || c.Query != "") {
+		return errors.New("with-show-context is only supported for the tracks list")
+	}
+	if c.EraCounts && (path != erasPath || c.Query != "") {
+		return errors.New("counts is only supported for the eras list")
+	}
+	c.VenueSongs = *venueSongs
+	if c.VenueSongs && (path != venuesPath || c.Query == "") {
+		return errors.New("songs is only supported for venue details (e.g. venues -s the-academy --songs)")
+	}
+	if *limit < 0 {
+		return errors.New("limit can't be negative")
+	}
+	if *limit > 0 && !c.VenueSongs {
+		return errors.New("limit is only supported with venues --songs")
+	}
+	c.Limit = *limit
+	if *against != "" && path != tourDiffPath {
+		return errors.New("against is only supported for tour-diff")
+	}
+	if *maxTracks < 0 {
+		return errors.New("max-tracks can't be negative")
+	}
+	c.MaxTracks = *maxTracks
+	for _, p := range params {
+		param, err := parseParam(p)
+		if err != nil {
+			return err
+		}
+		c.Parameters = append(c.Parameters, param)
+	}
+	return nil
+}
+
+// parseParam checks that p is a key=value pair and escapes it for a query
+// string.
+func parseParam(p string) (string, error) {
+	key, value, ok := strings.Cut(p, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return "", fmt.Errorf("invalid param %q: use key=value", p)
+	}
+	return url.QueryEscape(key) + "=" + url.QueryEscape(value), nil
+}
+
+// setTheme turns on colored text output when asked (or when writing to a
+// terminal in auto mode), using the named theme.
+func (c *Client) setTheme(color, name string) error {
+	if name == "" {
+		name = defaultTheme
+	}
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (options are %s)", name, strings.Join(themeNames(), ", "))
+	}
+	c.theme = nil
+	switch color {
+	case "always":
+	case "never":
+		return nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" || !isTerminal(c.Output) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("color must be auto, always, or never, got %q", color)
+	}
+	c.theme = &t
+	return nil
+}
+
+// defaultPager is run when PAGER isn't set. -F quits right away when the
+// output fits on one screen, -R keeps colors, and -X leaves output on screen.
+const defaultPager = "less -FRX"
+
+// startPager points c.Output at a pager process when --pager is on, output
+// is text, and c.Output is a terminal. Otherwise output is left alone. The
+// returned func closes the pager and waits for it to exit.
+func (c *Client) startPager() (func() error, error) {
+	if !c.Pager || c.PrintJSON || c.PrintJSONL || c.PrintTSV || !isTerminal(c.Output) {
+		return func() error { return nil }, nil
+	}
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = strings.Fields(defaultPager)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = c.Output
+	cmd.Stderr = c.ErrOutput
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to pager: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start pager: %w", err)
+	}
+	c.Output = in
+	return func() error {
+		in.Close()
+		return cmd.Wait()
+	}, nil
+}
+
+// transport returns the client's transport for customizing, giving the
+// client its own copy first so http.DefaultClient is never modified.
+func (c *Client) transport() *http.Transport {
+	if t, ok := c.HTTPClient.Transport.(*http.Transport); ok && c.HTTPClient != http.DefaultClient {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	c.HTTPClient = &http.Client{
+		Transport: t,
+		Timeout:   c.HTTPClient.Timeout,
+	}
+	return t
+}
+
+// setProxy routes the client's requests through proxy. Without it, the
+// default transport already honors HTTP_PROXY/HTTPS_PROXY.
+func (c *Client) setProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid proxy %q: use a url like http://localhost:8080", proxy)
+	}
+	c.transport().Proxy = http.ProxyURL(u)
 	return nil
 }
 
+// defaultSortDirs is the direction used when a sort attribute is given
+// without one: newest (or biggest) first for dates and counts, alphabetical
+// for names. Other attributes are left to the server.
+var defaultSortDirs = map[string]string{
+	"date":         "desc",
+	"updated_at":   "desc",
+	"created_at":   "desc",
+	"duration":     "desc",
+	"likes_count":  "desc",
+	"shows_count":  "desc",
+	"tracks_count": "desc",
+	"name":         "asc",
+	"title":        "asc",
+	"slug":         "asc",
+}
+
 func (c *Client) parseSortParams(sortDir, sortAttr string) {
+	if sortDir != "asc" && sortDir != "desc" && sortAttr != "" {
+		sortDir = defaultSortDirs[sortAttr]
+	}
 	switch sortDir {
 	case "asc":
 		c.Parameters = append(c.Parameters, "sort_dir=asc")
@@ -142,6 +944,55 @@ func (c *Client) parseSortParams(sortDir, sortAttr string) {
 	}
 }
 
+// parseSince accepts either an absolute date (yyyy-mm-dd) or a span
+// relative to now, given in hours, days, or weeks (e.g. 24h, 7d, 2w).
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", since); err == nil {
+		return t, nil
+	}
+	units := map[byte]time.Duration{
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	unit, ok := units[since[len(since)-1]]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid since %q: use yyyy-mm-dd or a span like 7d", since)
+	}
+	n, err := strconv.Atoi(since[:len(since)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q: use yyyy-mm-dd or a span like 7d", since)
+	}
+	return now.Add(-time.Duration(n) * unit), nil
+}
+
+// parseTrackSort parses a --sort-tracks value like duration:desc. Without a
+// direction, durations sort longest first and titles alphabetically.
+func parseTrackSort(v string) (string, bool, error) {
+	attr, dir, hasDir := strings.Cut(v, ":")
+	if attr != "duration" && attr != "title" {
+		return "", false, fmt.Errorf("invalid sort-tracks %q, options are duration or title", attr)
+	}
+	switch {
+	case !hasDir:
+		return attr, attr == "duration", nil
+	case dir == "asc", dir == "desc":
+		return attr, dir == "desc", nil
+	}
+	return "", false, fmt.Errorf("invalid sort-tracks direction %q, options are asc or desc", dir)
+}
+
+func parseJSONIndent(indent string) (string, error) {
+	if indent == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(indent)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid json indent %q: use a number of spaces or tab", indent)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
 func (c *Client) parsePageParams(perPage, page int) {
 	if perPage != 20 && perPage > 0 {
 		c.Parameters = append(c.Parameters, fmt.Sprintf("per_page=%d", perPage))
@@ -157,242 +1008,1821 @@ func (c *Client) parseTag(tag string) {
 	}
 }
 
+// getAndPrintRaw prints the response for url as the api sent it. It's
+// fetched with Get, so it's retried, recorded and replayed offline the same
+// way, but a miss is only returned (run prints no search tips for it).
 func (c *Client) getAndPrintRaw(ctx context.Context, url string) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	g := &GenericResponse{}
+	if err := c.Get(ctx, url, partial(g)); err != nil {
+		return err
+	}
+	return printJSON(c.Output, c.withJSONRoot(g), c.JSONIndent)
+}
+
+func (c *Client) Get(ctx context.Context, url string, data any) error {
+	if c.Offline {
+		return c.getOffline(url, data)
+	}
+	return c.withRetries(ctx, http.MethodGet, url, func() (bool, error) {
+		return c.get(ctx, url, data)
+	})
+}
+
+// Post sends body as json to url, decoding the response into data when it
+// isn't nil. It's only retried with RetryNonIdempotent, since a write may
+// have landed before the failure.
+func (c *Client) Post(ctx context.Context, url string, body, data any) error {
+	if c.Offline {
+		return errors.New("can't send writes offline")
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("unable to encode request body: %w", err)
+	}
+	return c.withRetries(ctx, http.MethodPost, url, func() (bool, error) {
+		return c.post(ctx, url, b, data)
+	})
+}
+
+// post makes a single request for Post, reporting whether a failure is
+// worth retrying.
+func (c *Client) post(ctx context.Context, url string, body []byte, data any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("error building request: %w", err)
+		return false, fmt.Errorf("error building request: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
-	authToken := c.APIKey
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthorization(req)
 	req.Header.Set("User-Agent", "https://github.com/davemolk/phishin")
+	c.traceRequest(req)
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return ctx.Err() == nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			fmt.Fprint(os.Stderr, searchTips)
+	c.traceResponse(resp)
+	c.logResponse(ctx, req, resp, start)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, fmt.Errorf("unexpected response status: %q: %w", resp.Status, ErrUnauthorized)
+	case http.StatusNotFound:
+		return false, fmt.Errorf("unexpected response status: %q: %w", resp.Status, ErrNotFound)
+	default:
+		return retryableStatus(resp.StatusCode), fmt.Errorf("unexpected response status: %q", resp.Status)
+	}
+	if data == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	return false, json.NewDecoder(resp.Body).Decode(data)
+}
+
+// withRetries makes a request with attempt, retrying a failure attempt
+// reports as worth it, with backoff, until Retries or the budget runs out.
+// Only GET and HEAD are retried unless RetryNonIdempotent is set.
+func (c *Client) withRetries(ctx context.Context, method, url string, attempt func() (bool, error)) error {
+	for n := 0; ; n++ {
+		if err := c.requests.acquire(ctx, c.Parallel); err != nil {
+			return err
+		}
+		retry, err := attempt()
+		c.requests.release()
+		c.stats.request(err)
+		if err == nil || !retry || !c.retryable(method) || n >= c.Retries || !c.takeRetry() {
+			return err
+		}
+		c.logger().Info("retrying request", "method", method, "url", url, "retry", n+1, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.After(c.retryWait << n):
 		}
-		return fmt.Errorf("unexpected response status: %q", resp.Status)
 	}
-	g := &GenericResponse{}
-	if err = json.NewDecoder(resp.Body).Decode(g); err != nil {
-		return fmt.Errorf("unable to read response body: %w", err)
+}
+
+// retryable reports whether a request using method may be sent again.
+func (c *Client) retryable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
 	}
-	return printJSON(c.Output, g)
+	return c.RetryNonIdempotent
 }
 
-func (c *Client) Get(ctx context.Context, url string, data any) error {
-	if c.Debug {
-		fmt.Fprintln(c.Output, url)
+// requestLimit is a semaphore shared by everything a Client runs, so
+// --parallel caps the requests in flight even when work that fetches in
+// parallel (e.g. a show's downloads) is itself run in parallel. Only the
+// requests themselves hold a slot, so nesting can't deadlock.
+type requestLimit struct {
+	once  sync.Once
+	slots chan struct{}
+}
+
+// acquire waits for a slot, sizing the limit to n on first use. A nil
+// limit doesn't cap anything.
+func (l *requestLimit) acquire(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
 	}
+	l.once.Do(func() {
+		l.slots = make(chan struct{}, max(n, 1))
+	})
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *requestLimit) release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// takeRetry reports whether a retry is left in the command's budget,
+// using it up if so.
+func (c *Client) takeRetry() bool {
+	if c.RetryBudget == 0 {
+		return true
+	}
+	return atomic.AddInt32(&c.retriesUsed, 1) <= int32(c.RetryBudget)
+}
+
+// retryableStatus reports whether a request failing with code is worth
+// another try.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// get makes a single request for Get, reporting whether a failure is worth
+// retrying.
+func (c *Client) get(ctx context.Context, url string, data any) (bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("error building request: %w", err)
+		return false, fmt.Errorf("error building request: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
-	authToken := c.APIKey
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	c.setAuthorization(req)
 	req.Header.Set("User-Agent", "https://github.com/davemolk/phishin")
+	c.traceRequest(req)
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return ctx.Err() == nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.traceResponse(resp)
+	c.logResponse(ctx, req, resp, start)
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			fmt.Fprint(os.Stderr, searchTips)
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return false, fmt.Errorf("unexpected response status: %q: %w", resp.Status, ErrNotFound)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return false, fmt.Errorf("unexpected response status: %q: %w", resp.Status, ErrUnauthorized)
 		}
-		return fmt.Errorf("unexpected response status: %q", resp.Status)
-	}
-	return json.NewDecoder(resp.Body).Decode(data)
-}
-
-func (c *Client) run(ctx context.Context, path string) error {
-	url := c.FormatURL(path)
-	if c.RawOutput {
-		return c.getAndPrintRaw(ctx, url)
+		return retryableStatus(resp.StatusCode), fmt.Errorf("unexpected response status: %q", resp.Status)
 	}
-	var results PrettyPrinter
-	var err error
-	switch {
-	case path == erasPath && c.Query != "":
-		results, err = c.getEra(ctx, url)
+	if c.RecordDir != "" {
+		b, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("era details failure: %w", err)
+			return false, fmt.Errorf("unable to read response body: %w", err)
 		}
-	case path == erasPath:
-		results, err = c.getEras(ctx, url)
-		if err != nil {
-			return fmt.Errorf("eras list failure: %w", err)
+		if err := c.record(url, b); err != nil {
+			return false, err
 		}
-	case path == yearsPath && c.Query != "":
-		results, err = c.getYear(ctx, url)
-		if err != nil {
-			return fmt.Errorf("year details failure: %w", err)
+		return false, c.decode(bytes.NewReader(b), data)
+	}
+	return false, c.decode(resp.Body, data)
+}
+
+// decode reads a json response into data, rejecting fields data doesn't
+// have with StrictJSON. A partial target is always read leniently.
+func (c *Client) decode(r io.Reader, data any) error {
+	p, isPartial := data.(partialJSON)
+	if isPartial {
+		data = p.v
+	}
+	if !c.StrictJSON || isPartial {
+		return json.NewDecoder(r).Decode(data)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("unable to read response body: %w", err)
+	}
+	if err := json.Unmarshal(b, data); err != nil {
+		return err
+	}
+	var tree any
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return err
+	}
+	if envelope, ok := tree.(map[string]any); ok {
+		for _, key := range envelopeFields {
+			delete(envelope, key)
 		}
-	case path == yearsPath:
-		results, err = c.getYears(ctx, url)
-		if err != nil {
-			return fmt.Errorf("years list failure: %w", err)
+	}
+	if field := unknownField(reflect.TypeOf(data), tree, ""); field != "" {
+		return fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+	return nil
+}
+
+// partialJSON marks a decode target that models only part of a response on
+// purpose (e.g. just a show's date), so StrictJSON leaves it alone.
+type partialJSON struct {
+	v any
+}
+
+// partial wraps v for Get as a partialJSON.
+func partial(v any) partialJSON {
+	return partialJSON{v: v}
+}
+
+// envelopeFields are the keys the api puts around every response's data.
+// Lists model the paging ones, but they aren't drift in a detail response.
+var envelopeFields = []string{"success", "total_entries", "total_pages", "page", "updated_at"}
+
+// unknownField is the path (e.g. data.tracks[].tags[].color) of the first
+// field in the decoded json value v that t has nowhere to put, or "" if t
+// models all of them. Types that decode themselves take anything.
+func unknownField(t reflect.Type, v any, path string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return ""
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			for _, key := range sortedKeys(v) {
+				if field := unknownField(t.Elem(), v[key], path+"."+key); field != "" {
+					return field
+				}
+			}
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for _, key := range sortedKeys(v) {
+				ft, ok := fields[strings.ToLower(key)]
+				if !ok {
+					return strings.TrimPrefix(path+"."+key, ".")
+				}
+				if field := unknownField(ft, v[key], path+"."+key); field != "" {
+					return field
+				}
+			}
 		}
-	case path == songsPath && c.Query != "":
-		results, err = c.getSong(ctx, url)
-		if err != nil {
-			return fmt.Errorf("song details failure: %w", err)
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, elem := range v {
+				if field := unknownField(t.Elem(), elem, path+"[]"); field != "" {
+					return field
+				}
+			}
 		}
-	case path == songsPath:
-		results, err = c.getSongs(ctx, url)
-		if err != nil {
-			return fmt.Errorf("songs list failure: %w", err)
+	}
+	return ""
+}
+
+// jsonFields maps the lowercased json name of each of struct t's fields to
+// its type, following encoding/json: embedded structs' fields are promoted
+// unless a shallower field has the name.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
 		}
-	case path == toursPath && c.Query != "":
-		results, err = c.getTour(ctx, url)
-		if err != nil {
-			return fmt.Errorf("tour details failure: %w", err)
+		if name == "-" || !f.IsExported() {
+			continue
 		}
-	case path == toursPath:
-		results, err = c.getTours(ctx, url)
-		if err != nil {
-			return fmt.Errorf("tours list failure: %w", err)
+		if name == "" {
+			name = f.Name
 		}
-	case path == venuesPath && c.Query != "":
-		results, err = c.getVenue(ctx, url)
-		if err != nil {
-			return fmt.Errorf("venue details failure: %w", err)
+		fields[strings.ToLower(name)] = f.Type
+	}
+	for _, et := range embedded {
+		for name, ft := range jsonFields(et) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = ft
+			}
 		}
-	case path == venuesPath:
-		results, err = c.getVenues(ctx, url)
-		if err != nil {
-			return fmt.Errorf("venues list failure: %w", err)
+	}
+	return fields
+}
+
+// sortedKeys is m's keys in order, so unknownField reports the same field
+// each run.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setAuthorization adds the api key to req. Without a key (--no-api-key) the
+// request goes without one, leaving the server to decide if it needs it.
+func (c *Client) setAuthorization(req *http.Request) {
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	}
+}
+
+// record saves a response body to RecordDir as a fixture named for the
+// endpoint, e.g. /shows/1995-12-31 is saved as shows-1995-12-31.json.
+func (c *Client) record(rawURL string, body []byte) error {
+	name, err := c.recordName(rawURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.RecordDir, 0755); err != nil {
+		return fmt.Errorf("unable to create record directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.RecordDir, name), body, 0644); err != nil {
+		return fmt.Errorf("unable to record response: %w", err)
+	}
+	return nil
+}
+
+// recordName is the file a response for rawURL is recorded to. Query
+// parameters are added sorted, so e.g. /shows?tag=sbd&page=2 is saved as
+// shows_page=2_tag=sbd.json whatever order they were sent in.
+func (c *Client) recordName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse url to record: %w", err)
+	}
+	endpoint := strings.TrimPrefix(u.Path, c.basePath())
+	endpoint = strings.ReplaceAll(strings.Trim(endpoint, "/"), "/", "-")
+	if endpoint == "" {
+		endpoint = "index"
+	}
+	if query := u.Query().Encode(); query != "" {
+		endpoint += "_" + strings.ReplaceAll(query, "&", "_")
+	}
+	return endpoint + ".json", nil
+}
+
+// getOffline decodes the response recorded for url into data instead of
+// making a request.
+func (c *Client) getOffline(url string, data any) error {
+	name, err := c.recordName(url)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(filepath.Join(c.RecordDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w (record it with --record %s)", url, ErrNotCached, c.RecordDir)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read recorded response: %w", err)
+	}
+	return c.decode(bytes.NewReader(b), data)
+}
+
+// basePath is the path of BaseURL, e.g. /api/v1, which endpoints follow.
+func (c *Client) basePath() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// batchSeparator is printed between the outputs of batch commands.
+const batchSeparator = "---"
+
+// runBatch runs each line of the file as a phishin command (minus the
+// leading "phishin"), each on a fresh client from c. Blank lines and lines
+// starting with # are skipped.
+func (c *Client) runBatch(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open batch file: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	ran := 0
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitCommandLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		if args[0] == "phishin" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		lc := c.batchClient()
+		if err := lc.fromArgs(args); err != nil {
+			return fmt.Errorf("line %d: unable to parse args: %w", n, err)
+		}
+		if lc.Watch != 0 {
+			return fmt.Errorf("line %d: watch never finishes, so it can't be used in a batch", n)
+		}
+		if lc.MetricsFile != "" {
+			return fmt.Errorf("line %d: metrics are written when a run ends, so --metrics-file can't be used in a batch", n)
+		}
+		if lc.Pager {
+			return fmt.Errorf("line %d: a batch's output goes to one place, so --pager can't be used in a batch", n)
+		}
+		if lc.APIKey == "" && !lc.NoAPIKey {
+			return fmt.Errorf("line %d: no api key, set PHISHIN_API_KEY or use --api-key (or --no-api-key)", n)
+		}
+		lc.ErrGroup.SetLimit(lc.Parallel)
+		if ran != 0 {
+			fmt.Fprintln(c.Output, batchSeparator)
+		}
+		ran++
+		start := time.Now()
+		err = lc.run(ctx, args[0])
+		if err == nil {
+			err = lc.waitForDownloads(start)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read batch file: %w", err)
+	}
+	return nil
+}
+
+// batchClient is a fresh client for one line of a batch, so no flag from
+// an earlier line carries over. It starts from c's api key, base url, and
+// http client, the settings c had before any flags, and shares its output.
+func (c *Client) batchClient() *Client {
+	lc := NewClient(c.APIKey, c.Output)
+	lc.ErrOutput = c.ErrOutput
+	lc.BaseURL = c.BaseURL
+	lc.HTTPClient = c.HTTPClient
+	// --insecure and --proxy change the transport in place, so each line
+	// gets its own copy
+	if t, ok := c.HTTPClient.Transport.(*http.Transport); ok && c.HTTPClient != http.DefaultClient {
+		hc := *c.HTTPClient
+		hc.Transport = t.Clone()
+		lc.HTTPClient = &hc
+	}
+	return lc
+}
+
+// splitCommandLine splits a command into its arguments on whitespace, keeping
+// single or double quoted arguments (e.g. -s "harry hood") together.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// defaultLogLevel is the minimum level logged without --log-level or --debug.
+const defaultLogLevel = slog.LevelWarn
+
+// newLogger returns a logger writing text records at level and above to w.
+// Record times are dropped, since someone is watching them as they happen.
+func newLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// logger returns c.Logger, falling back to ErrOutput at the default level
+// (or debug with --debug) when it isn't set.
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	level := defaultLogLevel
+	if c.Debug {
+		level = slog.LevelDebug
+	}
+	return newLogger(c.ErrOutput, level)
+}
+
+// runStats counts a run's requests, failed requests, and downloaded bytes.
+// Requests can be concurrent, so the counts are updated atomically.
+type runStats struct {
+	requests int64
+	errors   int64
+	bytes    int64
+}
+
+// request counts a request, and a failure if err isn't nil.
+func (s *runStats) request(err error) {
+	atomic.AddInt64(&s.requests, 1)
+	if err != nil {
+		atomic.AddInt64(&s.errors, 1)
+	}
+}
+
+func (s *runStats) downloaded(n int64) {
+	atomic.AddInt64(&s.bytes, n)
+}
+
+// writeMetrics writes the run's stats, and how long it took, in the
+// prometheus text format.
+func (s *runStats) writeMetrics(w io.Writer, elapsed time.Duration) error {
+	metrics := []struct {
+		name, kind, help string
+		value            string
+	}{
+		{"phishin_requests_total", "counter", "Requests made, including retries and downloads.", strconv.FormatInt(atomic.LoadInt64(&s.requests), 10)},
+		{"phishin_request_errors_total", "counter", "Requests that failed.", strconv.FormatInt(atomic.LoadInt64(&s.errors), 10)},
+		{"phishin_downloaded_bytes_total", "counter", "Bytes of mp3s (and cover art) downloaded.", strconv.FormatInt(atomic.LoadInt64(&s.bytes), 10)},
+		{"phishin_run_duration_seconds", "gauge", "How long the run took.", strconv.FormatFloat(elapsed.Seconds(), 'f', 3, 64)},
+	}
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", m.name, m.help, m.name, m.kind, m.name, m.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadStats counts a run's finished downloads, which can be concurrent,
+// so the counts are updated atomically.
+type downloadStats struct {
+	files   int64
+	failed  int64
+	bytes   int64
+	already int64
+}
+
+// finished counts a download once it's done retrying, as a failure if err
+// isn't nil.
+func (s *downloadStats) finished(d DownloadedFile, err error) {
+	if err != nil {
+		atomic.AddInt64(&s.failed, 1)
+		return
+	}
+	atomic.AddInt64(&s.files, 1)
+	atomic.AddInt64(&s.bytes, d.Size)
+}
+
+// skipped counts a file SkipExisting found already downloaded.
+func (s *downloadStats) skipped() {
+	atomic.AddInt64(&s.already, 1)
+}
+
+func (s *downloadStats) summary(elapsed time.Duration) string {
+	summary := fmt.Sprintf("downloaded %d files (%s) in %s, %d failed",
+		atomic.LoadInt64(&s.files),
+		humanizeBytes(atomic.LoadInt64(&s.bytes)),
+		elapsed.Round(time.Millisecond),
+		atomic.LoadInt64(&s.failed),
+	)
+	if already := atomic.LoadInt64(&s.already); already != 0 {
+		summary += fmt.Sprintf(", %d already downloaded", already)
+	}
+	return summary
+}
+
+// waitForDownloads waits for the downloads a command queued, then with
+// DownloadSummary prints how they went (failures included) to ErrOutput.
+func (c *Client) waitForDownloads(start time.Time) error {
+	err := c.ErrGroup.Wait()
+	if c.DownloadSummary {
+		fmt.Fprintln(c.ErrOutput, c.downloads.summary(time.Since(start)))
+	}
+	return err
+}
+
+// writeMetricsFile writes the run's metrics to MetricsFile.
+func (c *Client) writeMetricsFile(elapsed time.Duration) error {
+	f, err := os.Create(c.MetricsFile)
+	if err != nil {
+		return fmt.Errorf("unable to create metrics file: %w", err)
+	}
+	if err := c.stats.writeMetrics(f, elapsed); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write metrics: %w", err)
+	}
+	return f.Close()
+}
+
+// logResponse logs a completed request at the debug level.
+func (c *Client) logResponse(ctx context.Context, req *http.Request, resp *http.Response, start time.Time) {
+	c.logger().DebugContext(ctx, "request",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.StatusCode,
+		"elapsed", time.Since(start).Round(time.Millisecond),
+	)
+}
+
+// traceRequest logs the request line and headers to ErrOutput when tracing,
+// redacting the api key.
+func (c *Client) traceRequest(req *http.Request) {
+	if !c.Trace {
+		return
+	}
+	fmt.Fprintf(c.ErrOutput, "> %s %s\n", req.Method, req.URL)
+	traceHeaders(c.ErrOutput, ">", req.Header)
+}
+
+// traceResponse logs the response status and headers to ErrOutput when tracing.
+func (c *Client) traceResponse(resp *http.Response) {
+	if !c.Trace {
+		return
+	}
+	fmt.Fprintf(c.ErrOutput, "< %s %s\n", resp.Proto, resp.Status)
+	traceHeaders(c.ErrOutput, "<", resp.Header)
+}
+
+func traceHeaders(w io.Writer, prefix string, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			if k == "Authorization" {
+				v = redactAuthorization(v)
+			}
+			fmt.Fprintf(w, "%s %s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+// redactAuthorization hides the credentials in an Authorization header value,
+// keeping the scheme (e.g. "Bearer").
+func redactAuthorization(v string) string {
+	scheme, _, found := strings.Cut(v, " ")
+	if !found {
+		return "[REDACTED]"
+	}
+	return scheme + " [REDACTED]"
+}
+
+func (c *Client) run(ctx context.Context, path string) error {
+	if c.RawOutput {
+		return c.getAndPrintRaw(ctx, c.FormatURL(path))
+	}
+	if c.Watch > 0 {
+		return c.watch(ctx, path)
+	}
+	results, err := c.Result(ctx, path)
+	if errors.Is(err, ErrNotFound) {
+		fmt.Fprint(c.ErrOutput, searchTips)
+	}
+	// nil without an error is a search without results, in text output
+	if err != nil || results == nil {
+		return err
+	}
+	return c.printResults(results)
+}
+
+// watch prints the most recent show, then checks for a newer one every
+// c.Watch until ctx is done, printing each (after a --- line, as in batch
+// output). A failed check is logged and tried again next time.
+func (c *Client) watch(ctx context.Context, path string) error {
+	latest := 0
+	for {
+		results, err := c.Result(ctx, path)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			c.logger().Warn("unable to check for a new show", "err", err)
+		} else if shows, ok := results.(ShowsOutput); ok && len(shows.Shows) != 0 && shows.Shows[0].ID != latest {
+			if latest != 0 {
+				fmt.Fprintln(c.Output, batchSeparator)
+			}
+			latest = shows.Shows[0].ID
+			if err := c.printResults(shows); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.After(c.Watch):
+		}
+	}
+}
+
+// Result runs the command for path with the client's settings (e.g. from
+// fromArgs), returning its results rather than printing them, for callers
+// that render results themselves. The result is the command's output type,
+// e.g. ShowsOutput for a shows list, and nil for a search without results.
+// Raw output (--raw) is only printed, so isn't supported.
+func (c *Client) Result(ctx context.Context, path string) (PrettyPrinter, error) {
+	if c.RawOutput {
+		return nil, errors.New("raw output can only be printed")
+	}
+	url := c.FormatURL(path)
+	if c.Share {
+		share, err := c.getShare(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("share failure: %w", err)
+		}
+		return share, nil
+	}
+	if c.Count {
+		count, err := c.getCount(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("count failure: %w", err)
+		}
+		return count, nil
+	}
+	return c.result(ctx, path, url)
+}
+
+// result fetches the results of the command for path from url.
+func (c *Client) result(ctx context.Context, path, url string) (PrettyPrinter, error) {
+	var results PrettyPrinter
+	var err error
+	switch {
+	case path == erasPath && c.Query != "":
+		results, err = c.getEra(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("era details failure: %w", err)
+		}
+	case path == erasPath && c.EraCounts:
+		results, err = c.getEraCounts(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("era counts failure: %w", err)
+		}
+	case path == erasPath:
+		results, err = c.getEras(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("eras list failure: %w", err)
+		}
+	case path == yearsPath && c.Query != "":
+		results, err = c.getYear(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("year details failure: %w", err)
+		}
+	case path == yearsPath:
+		results, err = c.getYears(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("years list failure: %w", err)
+		}
+	case path == songsPath && c.Query != "":
+		results, err = c.getSong(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("song details failure: %w", err)
+		}
+	case path == songsPath && c.Breakdown && c.All:
+		songs, err := c.getAllSongs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("songs list failure: %w", err)
+		}
+		results = breakDownSongs(convertSongsToOutput(songs))
+	case path == songsPath:
+		songs, err := c.getSongs(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("songs list failure: %w", err)
+		}
+		results = songs
+		if c.ByArtist {
+			results = groupSongsByArtist(songs.Songs)
+		}
+		if c.Breakdown {
+			results = breakDownSongs(songs.Songs)
+		}
+	case path == likePath:
+		id, _ := strconv.Atoi(c.Args[1])
+		results, err = c.like(ctx, c.Args[0], id)
+		if err != nil {
+			return nil, fmt.Errorf("like failure: %w", err)
+		}
+	case path == tourDiffPath:
+		results, err = c.tourDiff(ctx, c.Args[0], c.Against)
+		if err != nil {
+			return nil, fmt.Errorf("tour diff failure: %w", err)
+		}
+	case path == resolvePath:
+		results, err = c.resolve(ctx, c.Args[0], c.Args[1])
+		if err != nil {
+			return nil, fmt.Errorf("resolve failure: %w", err)
+		}
+	case path == reportPath:
+		results, err = c.getIncompleteReport(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("incomplete report failure: %w", err)
+		}
+	case path == authCheckPath:
+		results, err = c.authCheck(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("auth check failure: %w", err)
+		}
+	case path == auditPath:
+		results, err = c.getToursAudit(ctx, c.FormatURL(toursPath))
+		if err != nil {
+			return nil, fmt.Errorf("tours audit failure: %w", err)
+		}
+	case path == toursPath && c.Query != "":
+		tour, err := c.getTour(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("tour details failure: %w", err)
+		}
+		// a tour's shows come without tracks, so fetch each one for its setlist
+		if c.Expand && c.Verbose {
+			ids := make([]int, 0, len(tour.Shows))
+			for _, show := range tour.Shows {
+				ids = append(ids, show.ID)
+			}
+			shows, err := c.getShowsByID(ctx, ids)
+			if err != nil {
+				return nil, fmt.Errorf("tour shows failure: %w", err)
+			}
+			tour.Shows = shows.Shows
+			tour.expanded = true
+		}
+		results = tour
+	case path == toursPath:
+		results, err = c.getTours(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("tours list failure: %w", err)
+		}
+	case path == venuesPath && c.Query != "" && c.VenueSongs:
+		results, err = c.getVenueSongs(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("venue songs failure: %w", err)
+		}
+	case path == venuesPath && c.Query != "":
+		results, err = c.getVenue(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("venue details failure: %w", err)
+		}
+	case path == venuesPath:
+		venues, err := c.getVenues(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("venues list failure: %w", err)
+		}
+		if c.Dedupe {
+			venues.Venues = dedupeVenues(venues.Venues)
+		}
+		results = venues
+		if c.VenuesBy != "" {
+			results = groupVenuesByLocation(venues.Venues, c.VenuesBy)
 		}
 	case (path == showsPath || path == showOnDatePath || path == randomShowPath) && c.Query != "":
-		results, err = c.getShow(ctx, url)
+		if c.Adjacent != 0 {
+			date, err := c.adjacentShowDate(ctx, c.Query, c.Adjacent)
+			if err != nil {
+				return nil, fmt.Errorf("show details failure: %w", err)
+			}
+			url = c.entityURL(showsPath, date)
+		}
+		show, err := c.getShow(ctx, url)
+		if err == nil {
+			show, err = c.withEras(ctx, show)
+		}
 		if err != nil {
-			return fmt.Errorf("show details failure: %w", err)
+			return nil, fmt.Errorf("show details failure: %w", err)
+		}
+		results = show
+		if c.NestedSets && c.PrintJSON {
+			results = convertShowToNestedOutput(show)
+		}
+		if c.SetlistNotation {
+			results = setlistNotation(show)
+		}
+	case path == randomShowPath:
+		var show ShowOutput
+		if c.RandomYear != "" || c.RandomEra != "" {
+			show, err = c.getConstrainedRandomShow(ctx)
+		} else {
+			show, err = c.getShow(ctx, url)
+		}
+		if err == nil {
+			show, err = c.withEras(ctx, show)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("random show failure: %w", err)
+		}
+		results = show
+		if c.NestedSets && c.PrintJSON {
+			results = convertShowToNestedOutput(show)
+		}
+		if c.SetlistNotation {
+			results = setlistNotation(show)
 		}
 	case path == showsPath || path == showsDayOfYearPath:
-		results, err = c.getShows(ctx, url)
+		shows, err := c.getShows(ctx, url)
 		if err != nil {
-			return fmt.Errorf("shows list failure: %w", err)
+			return nil, fmt.Errorf("shows list failure: %w", err)
+		}
+		if c.MarkTours {
+			ids := make([]int, 0, len(shows.Shows))
+			for _, show := range shows.Shows {
+				ids = append(ids, show.TourID)
+			}
+			shows.tourNames, err = c.getTourNamesByID(ctx, ids)
+			if err != nil {
+				return nil, fmt.Errorf("shows list failure: %w", err)
+			}
+		}
+		results = shows
+		if c.GroupBy == groupByVenue {
+			results = groupShowsByVenue(shows.Shows)
 		}
 	case path == tracksPath && c.Query != "":
 		results, err = c.getTrack(ctx, url)
 		if err != nil {
-			return fmt.Errorf("track details failure: %w", err)
+			return nil, fmt.Errorf("track details failure: %w", err)
 		}
 	case path == tracksPath:
 		results, err = c.getTracks(ctx, url)
 		if err != nil {
-			return fmt.Errorf("tracks list failure: %w", err)
+			return nil, fmt.Errorf("tracks list failure: %w", err)
 		}
 	case path == searchPath:
-		results, err = c.getSearch(ctx, url)
+		search, err := c.getSearch(ctx, url)
+		// search misses are expected, so treat them as an empty result
+		// rather than an error
+		if errors.Is(err, ErrNotFound) {
+			fmt.Fprintf(c.ErrOutput, "no search results for %q\n", c.Query)
+			if !c.PrintJSON {
+				return nil, nil
+			}
+			err = nil
+		}
 		if err != nil {
-			return fmt.Errorf("search failure: %w", err)
+			return nil, fmt.Errorf("search failure: %w", err)
+		}
+		if c.NoTracks {
+			search.Results.Tracks = nil
+		}
+		search.term = c.Query
+		results = search
+		if c.Compact {
+			results = search.counts()
+		} else if c.Flatten && c.PrintJSON {
+			results, err = flattenSearch(search)
+			if err != nil {
+				return nil, fmt.Errorf("search failure: %w", err)
+			}
 		}
 	// case path == "playlists" && c.Query != "":
 
-	case path == tagsPath && c.Query != "":
-		results, err = c.getTag(ctx, url)
-		if err != nil {
-			return fmt.Errorf("tag details failure: %w", err)
-		}
-	case path == tagsPath:
-		results, err = c.getTags(ctx, url)
-		if err != nil {
-			return fmt.Errorf("tags list failure: %w", err)
+	case path == tagsPath && c.Query != "":
+		tag, err := c.getTag(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("tag details failure: %w", err)
+		}
+		results = tag
+		if c.Expand {
+			results, err = c.getShowsByID(ctx, tag.ShowIds)
+			if err != nil {
+				return nil, fmt.Errorf("tag shows failure: %w", err)
+			}
+		}
+	case path == tagsPath:
+		results, err = c.getTags(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("tags list failure: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// printResults is PrintResults using the client's output settings.
+func (c *Client) printResults(pp PrettyPrinter) error {
+	w := c.Output
+	if c.ASCII {
+		w = &asciiWriter{w: w}
+	}
+	if c.PrintJSON && c.JSONIDStrings {
+		return printJSONQuotedIDs(w, c.withJSONRoot(newJSONEnvelope(pp)), c.JSONIndent)
+	}
+	if c.PrintJSON {
+		return printJSON(w, c.withJSONRoot(newJSONEnvelope(pp)), c.JSONIndent)
+	}
+	if c.PrintTSV {
+		opts := c.printOptions()
+		// escape codes would end up in the cells
+		opts.theme = nil
+		if cp, ok := pp.(configurable); ok {
+			pp = cp.withOptions(opts)
+		}
+		t, ok := pp.(tabular)
+		if !ok {
+			return errors.New("tsv output isn't supported for these results")
+		}
+		return printTSV(w, t)
+	}
+	if sp, ok := pp.(summarizer); ok && c.Summary {
+		fmt.Fprintln(w, sp.summary())
+		fmt.Fprintln(w)
+	}
+	if cp, ok := pp.(configurable); ok {
+		pp = cp.withOptions(c.printOptions())
+	}
+	if c.Highlight == "" {
+		return pp.PrettyPrint(w, c.Verbose)
+	}
+	hw := newHighlightWriter(w, c.Highlight, c.theme != nil)
+	if err := pp.PrettyPrint(hw, c.Verbose); err != nil {
+		return err
+	}
+	return hw.Flush()
+}
+
+// printOptions are the client's settings for the printers.
+func (c *Client) printOptions() printOptions {
+	return printOptions{
+		theme:        c.theme,
+		links:        c.Links,
+		columns:      c.Columns,
+		segues:       c.Segues,
+		jsonl:        c.PrintJSONL,
+		seconds:      c.DurationSeconds,
+		clock:        c.DurationClock,
+		mergeSets:    c.MergeSets,
+		includeEmpty: c.IncludeEmpty,
+		context:      c.ContextChars,
+		maxTracks:    c.MaxTracks,
+	}
+}
+
+// withJSONRoot nests data under the --json-root key, if there is one.
+func (c *Client) withJSONRoot(data any) any {
+	if c.JSONRoot == "" {
+		return data
+	}
+	return map[string]any{c.JSONRoot: data}
+}
+
+func (c *Client) getEras(ctx context.Context, url string) (ErasOutput, error) {
+	var resp ErasResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return nil, fmt.Errorf("unable to get eras list: %w", err)
+	}
+	o := make(ErasOutput, len(resp.Data))
+	for _, name := range knownEras {
+		o[name] = nil
+	}
+	for name, years := range resp.Data {
+		if c.NormalizeDates {
+			var err error
+			if years, err = expandYears(years); err != nil {
+				return nil, err
+			}
+		}
+		o[name] = years
+	}
+	return o, nil
+}
+
+// authCheck makes one small authenticated request (the eras list) to see
+// whether the api key is accepted.
+func (c *Client) authCheck(ctx context.Context) (AuthCheckOutput, error) {
+	var resp ErasResponse
+	if err := c.Get(ctx, fmt.Sprintf("%s/%s", c.BaseURL, erasPath), &resp); err != nil {
+		return AuthCheckOutput{}, err
+	}
+	return AuthCheckOutput{Valid: true}, nil
+}
+
+// getEraCounts gets the eras and the years with their show counts,
+// totaling each era's shows.
+func (c *Client) getEraCounts(ctx context.Context, url string) (EraCountsOutput, error) {
+	eras, err := c.getEras(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	years, err := c.getYears(ctx, fmt.Sprintf("%s/%s?include_show_counts=true", c.BaseURL, yearsPath))
+	if err != nil {
+		return nil, err
+	}
+	return countEraShows(eras, years.Years), nil
+}
+
+func (c *Client) getEra(ctx context.Context, url string) (EraOutput, error) {
+	var resp EraResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return EraOutput{}, fmt.Errorf("unable to get era details: %w", err)
+	}
+	o := EraOutput{
+		EraName: c.Query,
+		Years:   resp.Era,
+	}
+	if c.NormalizeDates {
+		years, err := expandYears(o.Years)
+		if err != nil {
+			return EraOutput{}, err
+		}
+		o.Years = years
+	}
+	return o, nil
+}
+
+// expandYears replaces any span of years (1983-1987) with each year in it.
+func expandYears(years []string) ([]string, error) {
+	expanded := make([]string, 0, len(years))
+	for _, y := range years {
+		first, last, err := parseYearBucket(y)
+		if err != nil {
+			return nil, err
+		}
+		for year := first; year <= last; year++ {
+			expanded = append(expanded, strconv.Itoa(year))
+		}
+	}
+	return expanded, nil
+}
+
+// normalizeYears replaces any span of years in the years list with an entry
+// for each year in it. The list only counts shows for the whole span, so
+// the span's shows are fetched and counted by year.
+func (c *Client) normalizeYears(ctx context.Context, years []Year) ([]Year, error) {
+	normalized := make([]Year, 0, len(years))
+	for _, y := range years {
+		first, last, err := parseYearBucket(y.Date)
+		if err != nil {
+			return nil, err
+		}
+		if first == last {
+			normalized = append(normalized, y)
+			continue
+		}
+		var resp YearResponse
+		url := fmt.Sprintf("%s/%s/%s", c.BaseURL, yearsPath, y.Date)
+		if err := c.Get(ctx, url, &resp); err != nil {
+			return nil, fmt.Errorf("unable to get shows for %s: %w", y.Date, err)
+		}
+		counts := make(map[string]int)
+		for _, show := range resp.Data {
+			counts[strings.SplitN(show.Date, "-", 2)[0]]++
+		}
+		for year := first; year <= last; year++ {
+			date := strconv.Itoa(year)
+			normalized = append(normalized, Year{Date: date, ShowCount: counts[date]})
+		}
+	}
+	return normalized, nil
+}
+
+func (c *Client) getYears(ctx context.Context, url string) (YearsOutput, error) {
+	var resp YearsResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return YearsOutput{}, fmt.Errorf("unable to get years list: %w", err)
+	}
+	o := YearsOutput{
+		TotalEntries: resp.TotalEntries,
+		TotalPages:   resp.TotalPages,
+		CurrentPage:  resp.Page,
+		Years:        resp.Data,
+	}
+	if c.NormalizeDates {
+		years, err := c.normalizeYears(ctx, o.Years)
+		if err != nil {
+			return YearsOutput{}, err
+		}
+		o.Years = years
+	}
+	if c.YearFrom != 0 || c.YearTo != 0 {
+		years, err := filterYears(o.Years, c.YearFrom, c.YearTo)
+		if err != nil {
+			return YearsOutput{}, err
+		}
+		o.Years = years
+		for _, y := range years {
+			o.ShowCount += y.ShowCount
+		}
+	}
+	return o, nil
+}
+
+// filterYears keeps the years that fall within from and to (0 for no bound).
+// A bucket like 1983-1987 is kept if any of its years are in range.
+func filterYears(years []Year, from, to int) ([]Year, error) {
+	filtered := make([]Year, 0, len(years))
+	for _, y := range years {
+		first, last, err := parseYearBucket(y.Date)
+		if err != nil {
+			return nil, err
+		}
+		if (from != 0 && last < from) || (to != 0 && first > to) {
+			continue
+		}
+		filtered = append(filtered, y)
+	}
+	return filtered, nil
+}
+
+// parseYearBucket returns the first and last year of a years list entry,
+// either a single year (1994) or a span (1983-1987).
+func parseYearBucket(date string) (int, int, error) {
+	start, end, found := strings.Cut(date, "-")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse year %q: %w", date, err)
+	}
+	if !found {
+		return first, first, nil
+	}
+	last, err := strconv.Atoi(end)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse year %q: %w", date, err)
+	}
+	return first, last, nil
+}
+
+// adjacentShowDate finds the date of the first show after (step 1) or
+// before (step -1) date. The api has no way to ask for that, so it walks
+// the years list out from date's year, fetching each year's shows until
+// one is past date.
+func (c *Client) adjacentShowDate(ctx context.Context, date string, step int) (string, error) {
+	list, err := c.getYears(ctx, fmt.Sprintf("%s/%s?include_show_counts=true", c.BaseURL, yearsPath))
+	if err != nil {
+		return "", err
+	}
+	type bucket struct {
+		Year
+		first, last int
+	}
+	years := make([]bucket, 0, len(list.Years))
+	for _, y := range list.Years {
+		first, last, err := parseYearBucket(y.Date)
+		if err != nil {
+			return "", err
+		}
+		years = append(years, bucket{Year: y, first: first, last: last})
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].first < years[j].first })
+	year, _ := strconv.Atoi(date[:4])
+	// start from date's year, or the nearest one in the direction of step
+	// when it had no shows (e.g. 2001)
+	i := 0
+	if step > 0 {
+		for i < len(years) && years[i].last < year {
+			i++
+		}
+	} else {
+		i = len(years) - 1
+		for i >= 0 && years[i].first > year {
+			i--
+		}
+	}
+	for ; i >= 0 && i < len(years); i += step {
+		if years[i].ShowCount == 0 {
+			continue
+		}
+		shows, err := c.getYear(ctx, fmt.Sprintf("%s/%s/%s", c.BaseURL, yearsPath, years[i].Date))
+		if err != nil {
+			return "", err
+		}
+		dates := make([]string, 0, len(shows.Shows))
+		for _, show := range shows.Shows {
+			dates = append(dates, show.Date)
+		}
+		sort.Strings(dates)
+		if step < 0 {
+			for j := len(dates) - 1; j >= 0; j-- {
+				if dates[j] < date {
+					return dates[j], nil
+				}
+			}
+			continue
+		}
+		for _, d := range dates {
+			if d > date {
+				return d, nil
+			}
+		}
+	}
+	direction := "after"
+	if step < 0 {
+		direction = "before"
+	}
+	return "", fmt.Errorf("no show %s %s: %w", direction, date, ErrNotFound)
+}
+
+func (c *Client) getYear(ctx context.Context, url string) (ShowsOutput, error) {
+	var resp YearResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return ShowsOutput{}, fmt.Errorf("unable to get year details: %w", err)
+	}
+	return convertShowsToOutput(c.filterShows(resp.Data)), nil
+}
+
+func (c *Client) getShows(ctx context.Context, url string) (ShowsOutput, error) {
+	var resp ShowsResponse
+	if c.HeadOnly {
+		var heads ShowHeadsResponse
+		if err := c.Get(ctx, url, &heads); err != nil {
+			return ShowsOutput{}, fmt.Errorf("unable to get shows list: %w", err)
+		}
+		resp = heads.shows()
+	} else if err := c.Get(ctx, url, &resp); err != nil {
+		return ShowsOutput{}, fmt.Errorf("unable to get shows list: %w", err)
+	}
+	o := convertShowsToOutput(c.filterShows(resp.Data))
+	o.TotalEntries = resp.TotalEntries
+	o.TotalPages = resp.TotalPages
+	o.CurrentPage = resp.Page
+	return o, nil
+}
+
+// filterShows applies the client-side filters to a list of shows.
+func (c *Client) filterShows(shows []Show) []Show {
+	if c.Since.IsZero() && !c.SBD && len(c.ExcludeTags) == 0 && c.MinLikes == 0 && c.MinTracks == 0 {
+		return shows
+	}
+	filtered := make([]Show, 0, len(shows))
+	for _, s := range shows {
+		if s.UpdatedAt.Before(c.Since) || (c.SBD && !s.Sbd) || c.excluded(s.Tags) || s.LikesCount < c.MinLikes {
+			continue
+		}
+		// a nil Tracks means the list left them out, not that there are none
+		if s.Tracks != nil && len(s.Tracks) < c.MinTracks {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
+
+// filterTracks applies the client-side filters to a list of tracks.
+func (c *Client) filterTracks(tracks []Track) []Track {
+	if c.Since.IsZero() && !c.SBD && len(c.ExcludeTags) == 0 && c.MinLikes == 0 {
+		return tracks
+	}
+	filtered := make([]Track, 0, len(tracks))
+	for _, t := range tracks {
+		if t.UpdatedAt.Before(c.Since) || (c.SBD && !hasTag(t.Tags, sbdTag)) || c.excluded(t.Tags) || t.LikesCount < c.MinLikes {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+// excluded reports whether tags include any tag dropped with --exclude-tag.
+func (c *Client) excluded(tags []Tag) bool {
+	for _, name := range c.ExcludeTags {
+		if hasTag(tags, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sbdTag is the tag phish.in uses for soundboard recordings.
+const sbdTag = "sbd"
+
+// hasTag reports whether tags include the named (or slugged) tag.
+func hasTag(tags []Tag, name string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(strings.ReplaceAll(t.Name, " ", "-"), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) getShow(ctx context.Context, url string) (ShowOutput, error) {
+	var resp ShowResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return ShowOutput{}, fmt.Errorf("unable to get show details: %w", err)
+	}
+	if c.Set != "" && !c.hasSet(resp.Data.Tracks) {
+		return ShowOutput{}, fmt.Errorf("no tracks found in set %q", c.Set)
+	}
+	if c.Download {
+		dir := c.showDir(resp.Data.Date)
+		mkdir := os.Mkdir
+		if c.SkipExisting {
+			// finishing an earlier download, so the directory may be there
+			mkdir = os.MkdirAll
+		}
+		if c.ByYear {
+			// the year's directory is shared with its other shows
+			if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+				return ShowOutput{}, fmt.Errorf("unable to create directory for downloaded files: %w", err)
+			}
+		}
+		if err := mkdir(dir, 0755); err != nil {
+			return ShowOutput{}, fmt.Errorf("unable to create directory for downloaded files: %w", err)
+		}
+		c.ErrGroup.Go(func() error {
+			return c.downloadShow(ctx, resp.Data, dir)
+		})
+	}
+	if c.Cover {
+		if err := c.downloadCover(ctx, resp.Data); err != nil {
+			return ShowOutput{}, err
+		}
+	}
+	o := convertShowToOutput(resp.Data)
+	if c.Set != "" {
+		tracks := make([]TrackOutput, 0, len(o.Tracks))
+		for _, t := range o.Tracks {
+			if c.inSet(t.SetName) {
+				tracks = append(tracks, t)
+			}
+		}
+		o.Tracks = tracks
+	}
+	if c.SortTracks != "" {
+		sortTracks(o.Tracks, c.SortTracks, c.SortTracksDesc)
+	}
+	if c.PrefetchVenue {
+		// the venue embedded in a show is partial, e.g. it has no show dates
+		venues, err := c.getVenuesByID(ctx, []int{o.VenueID})
+		if err != nil {
+			return ShowOutput{}, err
+		}
+		if venue, ok := venues[o.VenueID]; ok {
+			o.Venue = venue
+		}
+	}
+	return o, nil
+}
+
+// showDir is the directory a show's downloads go in: <date> under
+// DownloadDir, or with ByYear, <year>/<date>.
+func (c *Client) showDir(date string) string {
+	if year, _, ok := strings.Cut(date, "-"); c.ByYear && ok {
+		return filepath.Join(c.DownloadDir, year, date)
+	}
+	return filepath.Join(c.DownloadDir, date)
+}
+
+// withEras adds the eras to a show without cover art, for the placeholder
+// naming its era. That's only printed in verbose text output, so otherwise
+// the eras aren't fetched.
+func (c *Client) withEras(ctx context.Context, show ShowOutput) (ShowOutput, error) {
+	if !c.Verbose || c.PrintJSON || show.Cover != "" {
+		return show, nil
+	}
+	eras, err := c.getEras(ctx, fmt.Sprintf("%s/%s", c.BaseURL, erasPath))
+	if err != nil {
+		return ShowOutput{}, err
+	}
+	show.eras = eras
+	return show, nil
+}
+
+// downloadCover queues a download of the show's cover art to its directory
+// under DownloadDir, e.g. 1997-11-22/cover.jpg.
+func (c *Client) downloadCover(ctx context.Context, show Show) error {
+	if show.CoverArt == "" {
+		return fmt.Errorf("no cover art for %s", show.Date)
+	}
+	u, err := url.Parse(show.CoverArt)
+	if err != nil {
+		return fmt.Errorf("unable to parse cover art url: %w", err)
+	}
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		ext = ".jpg"
+	}
+	dir := c.showDir(show.Date)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create directory for cover art: %w", err)
+	}
+	c.ErrGroup.Go(func() error {
+		_, err := c.DownloadTrack(ctx, show.CoverArt, "cover"+ext, dir)
+		return err
+	})
+	return nil
+}
+
+// inSet reports whether a track in setName belongs to the set chosen
+// with --set, which is every track when no set was chosen.
+func (c *Client) inSet(setName string) bool {
+	return c.Set == "" || strings.EqualFold(setName, c.Set)
+}
+
+func (c *Client) hasSet(tracks []Track) bool {
+	for _, t := range tracks {
+		if c.inSet(t.SetName) {
+			return true
 		}
 	}
-	return PrintResults(c.Output, results, c.PrintJSON, c.Verbose)
+	return false
 }
 
-func (c *Client) getEras(ctx context.Context, url string) (ErasOutput, error) {
-	var resp ErasResponse
-	if err := c.Get(ctx, url, &resp); err != nil {
-		return ErasOutput{}, fmt.Errorf("unable to get eras list: %w", err)
+// getShowsByID fetches the details for each show id, making at most
+// c.Parallel requests at a time. Shows are returned in the order of ids.
+func (c *Client) getShowsByID(ctx context.Context, ids []int) (ShowsOutput, error) {
+	shows := make([]ShowOutput, len(ids))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, id := range ids {
+		// capture loop vars locally
+		i, id := i, id
+		g.Go(func() error {
+			var resp ShowResponse
+			url := fmt.Sprintf("%s/%s/%d", c.BaseURL, showsPath, id)
+			if err := c.Get(ctx, url, &resp); err != nil {
+				return fmt.Errorf("unable to get show %d: %w", id, err)
+			}
+			shows[i] = convertShowToOutput(resp.Data)
+			return nil
+		})
 	}
-	o := ErasOutput{
-		One:   resp.Data.One,
-		Two:   resp.Data.Two,
-		Three: resp.Data.Three,
-		Four:  resp.Data.Four,
+	if err := g.Wait(); err != nil {
+		return ShowsOutput{}, err
 	}
-	return o, nil
+	return ShowsOutput{Shows: shows}, nil
 }
 
-func (c *Client) getEra(ctx context.Context, url string) (EraOutput, error) {
-	var resp EraResponse
+func (c *Client) getTours(ctx context.Context, url string) (ToursOutput, error) {
+	var resp ToursResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
-		return EraOutput{}, fmt.Errorf("unable to get era details: %w", err)
-	}
-	o := EraOutput{
-		EraName: c.Query,
-		Years:   resp.Era,
+		return ToursOutput{}, fmt.Errorf("unable to get tours list: %w", err)
 	}
+	o := convertToursToOutput(resp.Data)
+	o.TotalEntries = resp.TotalEntries
+	o.TotalPages = resp.TotalPages
+	o.CurrentPage = resp.Page
 	return o, nil
 }
 
-func (c *Client) getYears(ctx context.Context, url string) (YearsOutput, error) {
-	var resp YearsResponse
+func (c *Client) getToursAudit(ctx context.Context, url string) (TourAuditOutput, error) {
+	var resp ToursResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
-		return YearsOutput{}, fmt.Errorf("unable to get years list: %w", err)
+		return TourAuditOutput{}, fmt.Errorf("unable to get tours list: %w", err)
 	}
-	o := YearsOutput{
-		Years: resp.Data,
+	return auditTours(resp.Data), nil
+}
+
+// getConstrainedRandomShow picks a random show from the year or era. The api's
+// random-show can't be constrained, so the candidates are fetched and the
+// pick happens here.
+func (c *Client) getConstrainedRandomShow(ctx context.Context) (ShowOutput, error) {
+	years := []string{c.RandomYear}
+	if c.RandomEra != "" {
+		era, err := c.getEra(ctx, fmt.Sprintf("%s/%s/%s", c.BaseURL, erasPath, c.RandomEra))
+		if err != nil {
+			return ShowOutput{}, err
+		}
+		years = era.Years
 	}
-	return o, nil
+	shows := make([][]Show, len(years))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, year := range years {
+		// capture loop vars locally
+		i, year := i, year
+		g.Go(func() error {
+			var resp YearResponse
+			url := fmt.Sprintf("%s/%s/%s", c.BaseURL, yearsPath, year)
+			if err := c.Get(gctx, url, &resp); err != nil {
+				return fmt.Errorf("unable to get shows for %s: %w", year, err)
+			}
+			shows[i] = resp.Data
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return ShowOutput{}, err
+	}
+	var candidates []Show
+	for _, s := range shows {
+		candidates = append(candidates, s...)
+	}
+	if len(candidates) == 0 {
+		return ShowOutput{}, errors.New("no shows to pick from")
+	}
+	return convertShowToOutput(candidates[c.Rand.Intn(len(candidates))]), nil
 }
 
-func (c *Client) getYear(ctx context.Context, url string) (ShowsOutput, error) {
-	var resp YearResponse
-	if err := c.Get(ctx, url, &resp); err != nil {
-		return ShowsOutput{}, fmt.Errorf("unable to get year details: %w", err)
+// combinesQueryAndParams reports whether the path's detail route (e.g.
+// songs/tweezer) takes parameters too. Most ignore or reject them.
+func combinesQueryAndParams(path string) bool {
+	switch path {
+	case songsPath, searchPath:
+		return true
 	}
-	return convertShowsToOutput(resp.Data), nil
+	return false
 }
 
-func (c *Client) getShows(ctx context.Context, url string) (ShowsOutput, error) {
-	var resp ShowsResponse
-	if err := c.Get(ctx, url, &resp); err != nil {
-		return ShowsOutput{}, fmt.Errorf("unable to get shows list: %w", err)
+// isList reports whether the command lists entities (with pagination).
+func (c *Client) isList(path string) bool {
+	switch path {
+	case showsDayOfYearPath:
+		return true
+	case showsPath, songsPath, venuesPath, tracksPath, toursPath, tagsPath:
+		return c.Query == ""
 	}
-	o := convertShowsToOutput(resp.Data)
-	o.TotalEntries = resp.TotalEntries
-	o.TotalPages = resp.TotalPages
-	o.CurrentPage = resp.Page
-	return o, nil
+	return false
 }
 
-func (c *Client) getShow(ctx context.Context, url string) (ShowOutput, error) {
-	var resp ShowResponse
-	if err := c.Get(ctx, url, &resp); err != nil {
-		return ShowOutput{}, fmt.Errorf("unable to get show details: %w", err)
+// getCount gets the total number of entries for a list, reading only the
+// first page's metadata.
+func (c *Client) getCount(ctx context.Context, url string) (CountOutput, error) {
+	var resp struct {
+		TotalEntries int `json:"total_entries"`
 	}
-	if c.Download {
-		if err := os.Mkdir(resp.Data.Date, 0755); err != nil {
-			return ShowOutput{}, fmt.Errorf("unable to create directory for downloaded files: %w", err)
+	if err := c.Get(ctx, url, partial(&resp)); err != nil {
+		return CountOutput{}, fmt.Errorf("unable to get count: %w", err)
+	}
+	return CountOutput{Count: resp.TotalEntries}, nil
+}
+
+// like likes the show or track with id for the api key's account.
+func (c *Client) like(ctx context.Context, entity string, id int) (LikeOutput, error) {
+	body := struct {
+		LikableType string `json:"likable_type"`
+		LikableID   int    `json:"likable_id"`
+	}{
+		// the api's names for them, i.e. Show and Track
+		LikableType: strings.ToUpper(entity[:1]) + strings.TrimSuffix(entity[1:], "s"),
+		LikableID:   id,
+	}
+	var resp struct {
+		Data struct {
+			LikesCount int `json:"likes_count"`
+		} `json:"data"`
+	}
+	if err := c.Post(ctx, fmt.Sprintf("%s/likes", c.BaseURL), body, &resp); err != nil {
+		return LikeOutput{}, fmt.Errorf("unable to like %s %d: %w", body.LikableType, id, err)
+	}
+	return LikeOutput{Entity: entity, ID: id, LikesCount: resp.Data.LikesCount}, nil
+}
+
+// shareable reports whether entities of the path have a phish.in page.
+func shareable(path string) bool {
+	switch path {
+	case showsPath, tracksPath, songsPath, venuesPath, toursPath:
+		return true
+	}
+	return false
+}
+
+// getShare builds the phish.in page for the queried entity. Pages are by
+// date and slug, so a show id or a track needs a lookup (as does a song,
+// venue, or tour id).
+func (c *Client) getShare(ctx context.Context, path string) (ShareOutput, error) {
+	switch path {
+	case showsPath:
+		if link := showLink(c.Query); link != "" {
+			return ShareOutput{URL: link}, nil
+		}
+		var resp struct {
+			Data struct {
+				Date string `json:"date"`
+			} `json:"data"`
+		}
+		if err := c.Get(ctx, c.entityURL(showsPath, c.Query), partial(&resp)); err != nil {
+			return ShareOutput{}, fmt.Errorf("unable to get show %s: %w", c.Query, err)
+		}
+		return ShareOutput{URL: showLink(resp.Data.Date)}, nil
+	case tracksPath:
+		var resp TrackResponse
+		if err := c.Get(ctx, c.entityURL(tracksPath, c.Query), &resp); err != nil {
+			return ShareOutput{}, fmt.Errorf("unable to get track %s: %w", c.Query, err)
 		}
-		for i, t := range resp.Data.Tracks {
-			// start track number with 1, capture loop vars locally
-			i, t := i+1, t
-			c.ErrGroup.Go(func() error {
-				fileName := fmt.Sprintf("%d-%s.mp3", i, t.Slug)
-				return c.DownloadTrack(ctx, t.Mp3, fileName, resp.Data.Date)
-			})
+		return ShareOutput{URL: fmt.Sprintf("%s/%s", showLink(resp.Data.ShowDate), resp.Data.Slug)}, nil
+	}
+	slug := c.Query
+	if _, err := strconv.Atoi(slug); err == nil {
+		ref, err := c.resolve(ctx, path, slug)
+		if err != nil {
+			return ShareOutput{}, err
 		}
+		slug = ref.Slug
 	}
-	return convertShowToOutput(resp.Data), nil
+	return ShareOutput{URL: fmt.Sprintf("%s/%s/%s", siteURL, path, slug)}, nil
 }
 
-func (c *Client) getTours(ctx context.Context, url string) (ToursOutput, error) {
-	var resp ToursResponse
+// resolvable reports whether entities of the path have both an id and a slug.
+func resolvable(path string) bool {
+	switch path {
+	case songsPath, toursPath, venuesPath, tagsPath:
+		return true
+	}
+	return false
+}
+
+// resolve looks up an entity by slug or id, returning both.
+func (c *Client) resolve(ctx context.Context, path, slugOrID string) (ResolveOutput, error) {
+	var resp EntityRefResponse
+	url := fmt.Sprintf("%s/%s/%s", c.BaseURL, path, slugOrID)
+	if err := c.Get(ctx, url, partial(&resp)); err != nil {
+		return ResolveOutput{}, fmt.Errorf("unable to get %s %s: %w", path, slugOrID, err)
+	}
+	name := resp.Data.Name
+	// songs have a title rather than a name
+	if name == "" {
+		name = resp.Data.Title
+	}
+	return ResolveOutput{
+		Entity: path,
+		ID:     resp.Data.ID,
+		Slug:   resp.Data.Slug,
+		Name:   name,
+	}, nil
+}
+
+// getIncompleteReport scans a year's shows (or every show with --all) for
+// missing audio.
+func (c *Client) getIncompleteReport(ctx context.Context) (IncompleteReportOutput, error) {
+	if c.All {
+		shows, err := c.getAllShows(ctx)
+		if err != nil {
+			return IncompleteReportOutput{}, err
+		}
+		return reportIncomplete(shows), nil
+	}
+	var resp YearResponse
+	url := fmt.Sprintf("%s/%s/%s", c.BaseURL, yearsPath, c.Args[1])
 	if err := c.Get(ctx, url, &resp); err != nil {
-		return ToursOutput{}, fmt.Errorf("unable to get tours list: %w", err)
+		return IncompleteReportOutput{}, fmt.Errorf("unable to get year details: %w", err)
+	}
+	return reportIncomplete(resp.Data), nil
+}
+
+// allShowsPerPage is the page size used when fetching every show (or song).
+const allShowsPerPage = 100
+
+// getAllShows fetches every page of the shows list, keeping the order.
+func (c *Client) getAllShows(ctx context.Context) ([]Show, error) {
+	pageURL := func(page int) string {
+		return fmt.Sprintf("%s/%s?per_page=%d&page=%d", c.BaseURL, showsPath, allShowsPerPage, page)
+	}
+	var first ShowsResponse
+	if err := c.Get(ctx, pageURL(1), &first); err != nil {
+		return nil, fmt.Errorf("unable to get shows page 1: %w", err)
+	}
+	if first.TotalPages <= 1 {
+		return first.Data, nil
+	}
+	pages := make([][]Show, first.TotalPages)
+	pages[0] = first.Data
+	g, ctx := errgroup.WithContext(ctx)
+	for i := 1; i < first.TotalPages; i++ {
+		// capture loop var locally
+		i := i
+		g.Go(func() error {
+			var resp ShowsResponse
+			if err := c.Get(ctx, pageURL(i+1), &resp); err != nil {
+				return fmt.Errorf("unable to get shows page %d: %w", i+1, err)
+			}
+			pages[i] = resp.Data
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	var shows []Show
+	for _, p := range pages {
+		shows = append(shows, p...)
 	}
-	return convertToursToOutput(resp.Data), nil
+	return shows, nil
 }
 
 func (c *Client) getTour(ctx context.Context, url string) (TourOutput, error) {
@@ -411,6 +2841,48 @@ func (c *Client) getTour(ctx context.Context, url string) (TourOutput, error) {
 	return o, nil
 }
 
+// tourDiff compares the songs played on tour and against. A tour's shows
+// usually come without tracks, so those are fetched one by one.
+func (c *Client) tourDiff(ctx context.Context, tour, against string) (TourDiffOutput, error) {
+	a, err := c.getTourWithTracks(ctx, tour)
+	if err != nil {
+		return TourDiffOutput{}, err
+	}
+	b, err := c.getTourWithTracks(ctx, against)
+	if err != nil {
+		return TourDiffOutput{}, err
+	}
+	return diffTours(a, b), nil
+}
+
+// getTourWithTracks gets a tour, fetching any of its shows that came
+// without their tracks.
+func (c *Client) getTourWithTracks(ctx context.Context, slugOrID string) (TourOutput, error) {
+	tour, err := c.getTour(ctx, c.entityURL(toursPath, slugOrID))
+	if err != nil {
+		return TourOutput{}, err
+	}
+	var ids []int
+	var missing []int
+	for i, show := range tour.Shows {
+		if len(show.Tracks) == 0 {
+			ids = append(ids, show.ID)
+			missing = append(missing, i)
+		}
+	}
+	if len(ids) == 0 {
+		return tour, nil
+	}
+	shows, err := c.getShowsByID(ctx, ids)
+	if err != nil {
+		return TourOutput{}, fmt.Errorf("unable to get %s shows: %w", tour.Name, err)
+	}
+	for j, i := range missing {
+		tour.Shows[i] = shows.Shows[j]
+	}
+	return tour, nil
+}
+
 func (c *Client) getVenues(ctx context.Context, url string) (VenuesOutput, error) {
 	var resp VenuesResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
@@ -436,6 +2908,90 @@ func (c *Client) getVenue(ctx context.Context, url string) (VenueOutput, error)
 	return convertVenueToOutput(resp.Data), nil
 }
 
+// getVenueSongs fetches a venue and its shows (only the first c.Limit,
+// with a limit), tallying the songs played across them.
+func (c *Client) getVenueSongs(ctx context.Context, url string) (VenueSongsOutput, error) {
+	var resp VenueResponse
+	if err := c.Get(ctx, url, &resp); err != nil {
+		return VenueSongsOutput{}, fmt.Errorf("unable to get venue details: %w", err)
+	}
+	ids := resp.Data.ShowIds
+	if c.Limit > 0 && len(ids) > c.Limit {
+		ids = ids[:c.Limit]
+	}
+	shows, err := c.getShowsByID(ctx, ids)
+	if err != nil {
+		return VenueSongsOutput{}, err
+	}
+	return tallyVenueSongs(resp.Data.Name, shows.Shows), nil
+}
+
+// getVenuesByID fetches the full details for each venue id, making at most
+// c.Parallel requests at a time. Duplicate ids are only fetched once, and
+// id 0, which shows without a venue carry, not at all.
+func (c *Client) getVenuesByID(ctx context.Context, ids []int) (map[int]VenueOutput, error) {
+	venues := make(map[int]VenueOutput, len(ids))
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] || id == 0 {
+			continue
+		}
+		seen[id] = true
+		// capture loop var locally
+		id := id
+		g.Go(func() error {
+			var resp VenueResponse
+			url := fmt.Sprintf("%s/%s/%d", c.BaseURL, venuesPath, id)
+			if err := c.Get(ctx, url, &resp); err != nil {
+				return fmt.Errorf("unable to get venue %d: %w", id, err)
+			}
+			mu.Lock()
+			venues[id] = convertVenueToOutput(resp.Data)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return venues, nil
+}
+
+// getTourNamesByID fetches the name of each tour id, making at most
+// c.Parallel requests at a time. Duplicate ids are only fetched once, and
+// id 0, which shows without a tour carry, not at all.
+func (c *Client) getTourNamesByID(ctx context.Context, ids []int) (map[int]string, error) {
+	names := make(map[int]string, len(ids))
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] || id == 0 {
+			continue
+		}
+		seen[id] = true
+		// capture loop var locally
+		id := id
+		g.Go(func() error {
+			var resp TourResponse
+			url := fmt.Sprintf("%s/%s/%d", c.BaseURL, toursPath, id)
+			if err := c.Get(ctx, url, &resp); err != nil {
+				return fmt.Errorf("unable to get tour %d: %w", id, err)
+			}
+			mu.Lock()
+			names[id] = resp.Data.Name
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
 func (c *Client) getTags(ctx context.Context, url string) (TagsOutput, error) {
 	var resp TagsResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
@@ -446,7 +3002,10 @@ func (c *Client) getTags(ctx context.Context, url string) (TagsOutput, error) {
 		tags = append(tags, convertTagListItemToOutput(t))
 	}
 	return TagsOutput{
-		Tags: tags,
+		TotalEntries: resp.TotalEntries,
+		TotalPages:   resp.TotalPages,
+		CurrentPage:  resp.Page,
+		Tags:         tags,
 	}, nil
 }
 
@@ -463,26 +3022,104 @@ func (c *Client) getSongs(ctx context.Context, url string) (SongsOutput, error)
 	if err := c.Get(ctx, url, &resp); err != nil {
 		return SongsOutput{}, fmt.Errorf("unable to get songs list: %w", err)
 	}
-	songs := make([]SongOutput, 0, len(resp.Data))
-	for _, s := range resp.Data {
-		song := convertSongToOutput(s)
-		songs = append(songs, song)
-	}
 	o := SongsOutput{
 		TotalEntries: resp.TotalEntries,
 		TotalPages:   resp.TotalPages,
 		CurrentPage:  resp.Page,
-		Songs:        songs,
+		Songs:        convertSongsToOutput(resp.Data),
 	}
 	return o, nil
 }
 
+func convertSongsToOutput(songs []Song) []SongOutput {
+	o := make([]SongOutput, 0, len(songs))
+	for _, s := range songs {
+		o = append(o, convertSongToOutput(s))
+	}
+	return o
+}
+
+// getAllSongs fetches every page of the songs list, keeping the order.
+func (c *Client) getAllSongs(ctx context.Context) ([]Song, error) {
+	pageURL := func(page int) string {
+		return fmt.Sprintf("%s/%s?per_page=%d&page=%d", c.BaseURL, songsPath, allShowsPerPage, page)
+	}
+	var first SongsResponse
+	if err := c.Get(ctx, pageURL(1), &first); err != nil {
+		return nil, fmt.Errorf("unable to get songs page 1: %w", err)
+	}
+	if first.TotalPages <= 1 {
+		return first.Data, nil
+	}
+	pages := make([][]Song, first.TotalPages)
+	pages[0] = first.Data
+	g, ctx := errgroup.WithContext(ctx)
+	for i := 1; i < first.TotalPages; i++ {
+		// capture loop var locally
+		i := i
+		g.Go(func() error {
+			var resp SongsResponse
+			if err := c.Get(ctx, pageURL(i+1), &resp); err != nil {
+				return fmt.Errorf("unable to get songs page %d: %w", i+1, err)
+			}
+			pages[i] = resp.Data
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	var songs []Song
+	for _, p := range pages {
+		songs = append(songs, p...)
+	}
+	return songs, nil
+}
+
 func (c *Client) getSong(ctx context.Context, url string) (SongOutput, error) {
 	var resp SongResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
 		return SongOutput{}, fmt.Errorf("unable to get song details: %w", err)
 	}
-	return convertSongToOutput(resp.Data), nil
+	if c.Download {
+		dir := filepath.Join(c.DownloadDir, resp.Data.Slug)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return SongOutput{}, fmt.Errorf("unable to create directory for downloaded files: %w", err)
+		}
+		c.ErrGroup.Go(func() error {
+			return c.downloadSong(ctx, resp.Data, dir)
+		})
+	}
+	song := convertSongToOutput(resp.Data)
+	if c.TrackPage == 0 && c.TrackPerPage == 0 {
+		return song, nil
+	}
+	return song.pageTracks(c.TrackPage, c.TrackPerPage)
+}
+
+// downloadSong downloads each of a song's tracks to dir, named by show
+// date (1994-10-31.mp3). A second version from the same show gets its
+// place in the setlist too (1994-10-31-27.mp3).
+func (c *Client) downloadSong(ctx context.Context, song Song, dir string) error {
+	perShow := make(map[string]int)
+	for _, t := range song.Tracks {
+		perShow[t.ShowDate]++
+	}
+	seen := make(map[string]bool)
+	g, ctx := errgroup.WithContext(ctx)
+	for _, t := range song.Tracks {
+		fileName := t.ShowDate + ".mp3"
+		if perShow[t.ShowDate] > 1 && seen[t.ShowDate] {
+			fileName = fmt.Sprintf("%s-%d.mp3", t.ShowDate, t.Position)
+		}
+		seen[t.ShowDate] = true
+		url := t.Mp3
+		g.Go(func() error {
+			_, err := c.DownloadTrack(ctx, url, fileName, dir)
+			return err
+		})
+	}
+	return g.Wait()
 }
 
 func (c *Client) getTracks(ctx context.Context, url string) (TracksOutput, error) {
@@ -490,22 +3127,62 @@ func (c *Client) getTracks(ctx context.Context, url string) (TracksOutput, error
 	if err := c.Get(ctx, url, &resp); err != nil {
 		return TracksOutput{}, fmt.Errorf("unable to get tracks list: %w", err)
 	}
-	o := convertTracksToOutput(resp.Data)
+	tracks := c.filterTracks(resp.Data)
+	o := convertTracksToOutput(tracks)
 	o.TotalEntries = resp.TotalEntries
 	o.TotalPages = resp.TotalPages
 	o.CurrentPage = resp.Page
+	if c.WithShowContext {
+		if err := c.addShowContext(ctx, tracks, o.Tracks); err != nil {
+			return TracksOutput{}, err
+		}
+	}
 	return o, nil
 }
 
+// addShowContext places each of tracks in its show, setting the context on
+// the matching entry of out. Each show is fetched once, however many of its
+// tracks are listed.
+func (c *Client) addShowContext(ctx context.Context, tracks []Track, out []TrackOutput) error {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, t := range tracks {
+		if !seen[t.ShowID] {
+			seen[t.ShowID] = true
+			ids = append(ids, t.ShowID)
+		}
+	}
+	shows, err := c.getShowsByID(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("unable to get the tracks' shows: %w", err)
+	}
+	byID := make(map[int]ShowOutput, len(shows.Shows))
+	for _, show := range shows.Shows {
+		byID[show.ID] = show
+	}
+	for i, t := range tracks {
+		out[i].ShowContext = newTrackShowContext(byID[t.ShowID], t.ID)
+	}
+	return nil
+}
+
 func (c *Client) getTrack(ctx context.Context, url string) (TrackOutput, error) {
 	var resp TrackResponse
 	if err := c.Get(ctx, url, &resp); err != nil {
 		return TrackOutput{}, fmt.Errorf("unable to get track details: %w", err)
 	}
 	if c.Download {
+		dir := c.DownloadDir
+		if c.ByYear {
+			dir = c.showDir(resp.Data.ShowDate)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return TrackOutput{}, fmt.Errorf("unable to create directory for downloaded files: %w", err)
+			}
+		}
 		c.ErrGroup.Go(func() error {
 			fileName := fmt.Sprintf("%s.mp3", resp.Data.Slug)
-			return c.DownloadTrack(ctx, resp.Data.Mp3, fileName, ".")
+			_, err := c.DownloadTrack(ctx, resp.Data.Mp3, fileName, dir)
+			return err
 		})
 	}
 	return convertTrackToOutput(resp.Data), nil
@@ -519,38 +3196,281 @@ func (c *Client) getSearch(ctx context.Context, url string) (SearchOutput, error
 	return convertSearchToSearchOutput(resp), nil
 }
 
+// downloadShow downloads each of the show's tracks into dir, then writes
+// the manifest (if requested) once every track has finished.
+func (c *Client) downloadShow(ctx context.Context, show Show, dir string) error {
+	var manifest Manifest
+	var urls []string
+	tracks := make([]Track, 0, len(show.Tracks))
+	for i, t := range show.Tracks {
+		if !c.inSet(t.SetName) {
+			continue
+		}
+		tracks = append(tracks, t)
+		// number files by their place in the whole show, starting with 1
+		manifest.Tracks = append(manifest.Tracks, ManifestTrack{
+			FileName: fmt.Sprintf("%d-%s.mp3", i+1, t.Slug),
+			Mp3:      t.Mp3,
+			Duration: convertMillisecondToConcertDuration(int64(t.Duration)),
+		})
+		urls = append(urls, t.Mp3)
+	}
+	// the show in the manifest only has the tracks downloaded (--set)
+	show.Tracks = tracks
+	manifest.Show = convertShowToOutput(show)
+	var previous map[string]ManifestTrack
+	if c.SkipExisting {
+		previous = readPreviousManifest(dir)
+	}
+	g, ctx := errgroup.WithContext(ctx)
+	for i := range manifest.Tracks {
+		track, url := &manifest.Tracks[i], urls[i]
+		if prev, ok := previous[track.FileName]; ok && prev.Mp3 == track.Mp3 {
+			if status, err := verifyFile(filepath.Join(dir, track.FileName), prev); err == nil && status == verifyOK {
+				track.Size = prev.Size
+				track.SHA256 = prev.SHA256
+				c.downloads.skipped()
+				continue
+			}
+		}
+		g.Go(func() error {
+			d, err := c.DownloadTrack(ctx, url, track.FileName, dir)
+			if err != nil {
+				return err
+			}
+			track.Size = d.Size
+			track.SHA256 = d.SHA256
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if !c.Manifest {
+		return nil
+	}
+	return writeManifest(dir, manifest)
+}
+
+// readPreviousManifest returns the tracks listed in the manifest from an
+// earlier download to dir by file name, or nil if there isn't one.
+func readPreviousManifest(dir string) map[string]ManifestTrack {
+	b, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	tracks := make(map[string]ManifestTrack, len(m.Tracks))
+	for _, t := range m.Tracks {
+		tracks[t.FileName] = t
+	}
+	return tracks
+}
+
+func writeManifest(dir string, m Manifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to convert manifest to bytes: %w", err)
+	}
+	p := filepath.Join(dir, manifestFile)
+	if err := os.WriteFile(p, b, 0644); err != nil {
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+	return nil
+}
+
+// verifyManifest checks the files in dir against the manifest written when
+// they were downloaded.
+func verifyManifest(dir string) (VerifyOutput, error) {
+	b, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return VerifyOutput{}, fmt.Errorf("unable to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return VerifyOutput{}, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+	o := VerifyOutput{
+		Dir:     dir,
+		Results: make([]VerifyResult, 0, len(m.Tracks)),
+	}
+	for _, t := range m.Tracks {
+		status, err := verifyFile(filepath.Join(dir, t.FileName), t)
+		if err != nil {
+			return VerifyOutput{}, err
+		}
+		o.Results = append(o.Results, VerifyResult{FileName: t.FileName, Status: status})
+	}
+	return o, nil
+}
+
+func verifyFile(path string, t ManifestTrack) (string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return verifyMissing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	if n != t.Size {
+		return verifySize, nil
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != t.SHA256 {
+		return verifyChecksum, nil
+	}
+	return verifyOK, nil
+}
+
 // todo think about cleanup for cancelled context?
 // todo handle progress counter differently when have concurrent downloads?
 // todo track percentage via ContentLength
-func (c *Client) DownloadTrack(ctx context.Context, url, fileName, dirName string) error {
+func (c *Client) DownloadTrack(ctx context.Context, url, fileName, dirName string) (DownloadedFile, error) {
+	d, skipped, err := c.downloadWithRetries(ctx, url, fileName, dirName)
+	if skipped {
+		c.downloads.skipped()
+	} else {
+		c.downloads.finished(d, err)
+	}
+	return d, err
+}
+
+// downloadWithRetries downloads url for DownloadTrack, reporting whether
+// it was skipped as already complete (SkipExisting). Failed attempts are
+// retried by withRetries, the same as other requests.
+func (c *Client) downloadWithRetries(ctx context.Context, url, fileName, dirName string) (DownloadedFile, bool, error) {
+	if c.Offline {
+		return DownloadedFile{}, false, errors.New("can't download offline")
+	}
 	p := filepath.Join(dirName, fileName)
+	if c.SkipExisting {
+		if err := c.requests.acquire(ctx, c.Parallel); err != nil {
+			return DownloadedFile{}, false, err
+		}
+		d, ok, err := c.existingDownload(ctx, url, p)
+		c.requests.release()
+		if err != nil {
+			return DownloadedFile{}, false, err
+		}
+		if ok {
+			return d, true, nil
+		}
+	}
+	var d DownloadedFile
+	err := c.withRetries(ctx, http.MethodGet, url, func() (bool, error) {
+		var retry bool
+		var err error
+		d, retry, err = c.downloadTrack(ctx, url, fileName, p)
+		return retry, err
+	})
+	if err != nil {
+		return DownloadedFile{}, false, err
+	}
+	return d, false, nil
+}
+
+// downloadTrack makes a single attempt at a download for DownloadTrack,
+// reporting whether a failure is worth retrying. Each attempt starts the
+// file over.
+func (c *Client) downloadTrack(ctx context.Context, url, fileName, p string) (DownloadedFile, bool, error) {
 	f, err := os.Create(p)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return DownloadedFile{}, false, fmt.Errorf("failed to create file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return DownloadedFile{}, false, fmt.Errorf("failed to create request: %w", err)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	c.traceRequest(req)
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to get response: %w", err)
+		return DownloadedFile{}, ctx.Err() == nil, fmt.Errorf("failed to get response: %w", err)
 	}
 	defer resp.Body.Close()
+	c.traceResponse(resp)
+	c.logResponse(ctx, req, resp, start)
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("received unexpected status code: %q", resp.Status)
+		return DownloadedFile{}, retryableStatus(resp.StatusCode), fmt.Errorf("received unexpected status code: %q", resp.Status)
+	}
+
+	var progress io.Writer = io.Discard
+	if !c.DownloadSummary {
+		progress = &WriteCounter{
+			Name:   fileName,
+			Output: c.ErrOutput,
+		}
 	}
+	var body io.Reader = resp.Body
+	if c.limiter != nil {
+		body = &rateLimitedReader{ctx: ctx, r: body, limiter: c.limiter}
+	}
+	hasher := sha256.New()
+	n, err := io.Copy(f, io.TeeReader(body, io.MultiWriter(progress, hasher)))
+	if !c.DownloadSummary {
+		fmt.Fprintln(c.ErrOutput)
+	}
+	c.stats.downloaded(n)
+	if err != nil {
+		return DownloadedFile{}, ctx.Err() == nil, fmt.Errorf("unable to copy data to file: %w", err)
+	}
+	return DownloadedFile{
+		Path:   p,
+		Size:   n,
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+	}, false, nil
+}
 
-	progress := &WriteCounter{
-		Name: fileName,
+// existingDownload reports whether the file at p is already a complete
+// download of url, going by the size the server reports for it.
+func (c *Client) existingDownload(ctx context.Context, url, p string) (DownloadedFile, bool, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return DownloadedFile{}, false, nil
 	}
-	_, err = io.Copy(f, io.TeeReader(resp.Body, progress))
-	fmt.Println()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
-		return fmt.Errorf("unable to copy data to file: %w", err)
+		return DownloadedFile{}, false, fmt.Errorf("failed to create request: %w", err)
 	}
-	return nil
+	c.traceRequest(req)
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	c.stats.request(err)
+	if err != nil {
+		return DownloadedFile{}, false, fmt.Errorf("failed to get response: %w", err)
+	}
+	_ = resp.Body.Close()
+	c.traceResponse(resp)
+	c.logResponse(ctx, req, resp, start)
+	// without a size to check against, download it again
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 || resp.ContentLength != info.Size() {
+		return DownloadedFile{}, false, nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return DownloadedFile{}, false, fmt.Errorf("unable to open %s: %w", p, err)
+	}
+	defer func() { _ = f.Close() }()
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, f)
+	if err != nil {
+		return DownloadedFile{}, false, fmt.Errorf("unable to read %s: %w", p, err)
+	}
+	c.logger().Info("skipping complete download", "file", p)
+	return DownloadedFile{
+		Path:   p,
+		Size:   n,
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+	}, true, nil
 }
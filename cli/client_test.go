@@ -3,14 +3,26 @@ package cli
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -78,13 +90,37 @@ func TestFormatURL(t *testing.T) {
 		}
 	})
 	t.Run("can't have query and parameters", func(t *testing.T) {
+		t.Parallel()
+		c := NewClient(dummy, os.Stdout)
+		query := "nectar-s"
+		c.Query = query
+		c.Parameters = []string{"per_page=3", "page=1", "sort_attr=date", "sort_dir=desc"}
+		got := c.FormatURL("venues")
+		want := fmt.Sprintf("https://phish.in/api/v1/venues/%s", query)
+		if got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	})
+	t.Run("songs combine query and parameters", func(t *testing.T) {
 		t.Parallel()
 		c := NewClient(dummy, os.Stdout)
 		query := "harry-hood"
 		c.Query = query
 		c.Parameters = []string{"per_page=3", "page=1", "sort_attr=date", "sort_dir=desc"}
 		got := c.FormatURL(endpoint)
-		want := fmt.Sprintf("https://phish.in/api/v1/%s/%s", endpoint, query)
+		want := fmt.Sprintf("https://phish.in/api/v1/%s/%s?per_page=3&page=1&sort_attr=date&sort_dir=desc", endpoint, query)
+		if got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	})
+	t.Run("search combines query and page parameters", func(t *testing.T) {
+		t.Parallel()
+		c := NewClient(dummy, os.Stdout)
+		if err := c.fromArgs([]string{"search", "-s", "bowie", "-pp", "5"}); err != nil {
+			t.Fatal(err)
+		}
+		got := c.FormatURL("search")
+		want := "https://phish.in/api/v1/search/bowie?per_page=5"
 		if got != want {
 			t.Errorf("got %s want %s", got, want)
 		}
@@ -99,6 +135,16 @@ func TestFromArgs(t *testing.T) {
 			t.Error("wanted error, got nil")
 		}
 	})
+	t.Run("parallel must be at least 1", func(t *testing.T) {
+		if err := c.fromArgs([]string{"shows", "-parallel", "0"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+	t.Run("manifest requires download", func(t *testing.T) {
+		if err := c.fromArgs([]string{"shows", "-s", "1994-10-31", "--manifest"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
 	t.Run("show-on-date errors with no query", func(t *testing.T) {
 		err := c.fromArgs([]string{"show-on-date", "-s", ""})
 		if err == nil {
@@ -580,6 +626,16 @@ func TestClientRun(t *testing.T) {
 			query:     "stash",
 			raw:       false,
 		},
+		{
+			name:      "track verbose",
+			serveFile: "../testdata/track.json",
+			path:      "tracks",
+			golden:    "track.verbose.golden",
+			json:      false,
+			verbose:   true,
+			query:     "stash",
+			raw:       false,
+		},
 		{
 			name:      "search",
 			serveFile: "../testdata/boulder_search.json",
@@ -601,6 +657,11 @@ func TestClientRun(t *testing.T) {
 		c.RawOutput = tc.raw
 		ts := httptest.NewTLSServer(http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
+				// a verbose show also gets the eras, to name its era
+				if tc.path == "shows" && r.URL.Path == "/eras" {
+					http.ServeFile(w, r, "../testdata/eras.json")
+					return
+				}
 				http.ServeFile(w, r, tc.serveFile)
 			}))
 		defer ts.Close()
@@ -630,10 +691,10 @@ func TestGetEras(t *testing.T) {
 	c.BaseURL = ts.URL
 	c.HTTPClient = ts.Client()
 	want := ErasOutput{
-		One:   []string{"1983-1987", "1988", "1989", "1990", "1991", "1992", "1993", "1994", "1995", "1996", "1997", "1998", "1999", "2000"},
-		Two:   []string{"2002", "2003", "2004"},
-		Three: []string{"2009", "2010", "2011", "2012", "2013", "2014", "2015", "2016", "2017", "2018", "2019", "2020"},
-		Four:  []string{"2021", "2022", "2023"},
+		"1.0": []string{"1983-1987", "1988", "1989", "1990", "1991", "1992", "1993", "1994", "1995", "1996", "1997", "1998", "1999", "2000"},
+		"2.0": []string{"2002", "2003", "2004"},
+		"3.0": []string{"2009", "2010", "2011", "2012", "2013", "2014", "2015", "2016", "2017", "2018", "2019", "2020"},
+		"4.0": []string{"2021", "2022", "2023"},
 	}
 	ctx := context.Background()
 	url := c.FormatURL("eras")
@@ -683,6 +744,9 @@ func TestGetYears(t *testing.T) {
 	c.BaseURL = ts.URL
 	c.HTTPClient = ts.Client()
 	want := YearsOutput{
+		TotalEntries: 1,
+		TotalPages:   1,
+		CurrentPage:  1,
 		Years: []Year{
 			{
 				Date:      "1983-1987",
@@ -731,11 +795,14 @@ func TestGetYear(t *testing.T) {
 				ID:         135,
 				Date:       "1994-04-04",
 				Duration:   "2h 40m",
+				durationMS: 9601071,
+				TourID:     22,
+				VenueID:    266,
 				Sbd:        true,
 				Remastered: false,
 				Tags: []Tag{
 					{
-						Name: "SBD", Group: "Audio",
+						ID: 1, Name: "SBD", Priority: 1, Group: "Audio", Color: "#888888",
 					},
 				},
 				VenueName: "The Flynn Theatre",
@@ -743,6 +810,9 @@ func TestGetYear(t *testing.T) {
 					Name:       "The Flynn Theatre",
 					Location:   "Burlington, VT",
 					ShowsCount: 4,
+					OtherNames: []string{},
+					Latitude:   44.475883,
+					Longitude:  -73.212072,
 				},
 				VenueLocation: "Burlington, VT",
 				Tracks: []TrackOutput{
@@ -753,9 +823,11 @@ func TestGetYear(t *testing.T) {
 						VenueLocation: "Burlington, VT",
 						Title:         "Divided Sky",
 						Duration:      "13m 31s",
+						durationMS:    811964,
 						SetName:       "Set 1",
 						Tags:          []Tag{},
 						Mp3:           "https://phish.in/audio/000/002/553/2553.mp3",
+						WaveformImage: "https://phish.in/audio/000/002/553/waveform-2553.png",
 					},
 					{
 						ID:            2554,
@@ -764,9 +836,11 @@ func TestGetYear(t *testing.T) {
 						VenueLocation: "Burlington, VT",
 						Title:         "Sample in a Jar",
 						Duration:      "4m 59s",
+						durationMS:    299781,
 						SetName:       "Set 1",
 						Tags:          []Tag{},
 						Mp3:           "https://phish.in/audio/000/002/554/2554.mp3",
+						WaveformImage: "https://phish.in/audio/000/002/554/waveform-2554.png",
 					},
 				},
 			},
@@ -803,12 +877,18 @@ func TestGetShows(t *testing.T) {
 				ID:         696,
 				Date:       "1990-04-05",
 				Duration:   "2h 27m",
+				durationMS: 8831401,
+				TourID:     8,
+				VenueID:    339,
 				Sbd:        true,
 				Remastered: false,
 				Tags: []Tag{
 					{
-						Name:  "SBD",
-						Group: "Audio",
+						ID:       1,
+						Name:     "SBD",
+						Priority: 1,
+						Color:    "#888888",
+						Group:    "Audio",
 					},
 				},
 				VenueName:     "J.J. McCabe's",
@@ -817,6 +897,9 @@ func TestGetShows(t *testing.T) {
 					Name:       "J.J. McCabe's",
 					Location:   "Boulder, CO",
 					ShowsCount: 1,
+					OtherNames: []string{},
+					Latitude:   40.014986,
+					Longitude:  -105.270546,
 				},
 				Tracks: []TrackOutput{
 					{
@@ -826,14 +909,19 @@ func TestGetShows(t *testing.T) {
 						VenueLocation: "Boulder, CO",
 						Title:         "Possum",
 						Duration:      "6m 48s",
+						durationMS:    408033,
 						SetName:       "Set 1",
 						Tags: []Tag{
 							{
-								Name:  "SBD",
-								Group: "Audio",
+								ID:       1,
+								Name:     "SBD",
+								Priority: 1,
+								Color:    "#888888",
+								Group:    "Audio",
 							},
 						},
-						Mp3: "https://phish.in/audio/000/014/073/14073.mp3",
+						Mp3:           "https://phish.in/audio/000/014/073/14073.mp3",
+						WaveformImage: "https://phish.in/audio/000/014/073/waveform-14073.png",
 					},
 					{
 						ID:            14074,
@@ -842,19 +930,28 @@ func TestGetShows(t *testing.T) {
 						VenueLocation: "Boulder, CO",
 						Title:         "Ya Mar",
 						Duration:      "7m 7s",
+						durationMS:    427024,
 						SetName:       "Set 1",
 						Tags: []Tag{
 							{
-								Name:  "SBD",
-								Group: "Audio",
+								ID:       1,
+								Name:     "SBD",
+								Priority: 1,
+								Color:    "#888888",
+								Group:    "Audio",
 							},
 							{
-								Name:  "Tease",
-								Group: "Song Content",
-								Notes: "Theme from Bonanza by Ray Evans and\n Jay Livingston",
+								ID:             17,
+								Name:           "Tease",
+								Priority:       15,
+								Color:          "#888888",
+								Group:          "Song Content",
+								Notes:          "Theme from Bonanza by Ray Evans and\n Jay Livingston",
+								StartsAtSecond: 306,
 							},
 						},
-						Mp3: "https://phish.in/audio/000/014/074/14074.mp3",
+						Mp3:           "https://phish.in/audio/000/014/074/14074.mp3",
+						WaveformImage: "https://phish.in/audio/000/014/074/waveform-14074.png",
 					},
 				},
 			},
@@ -891,12 +988,18 @@ func TestGetShow(t *testing.T) {
 		ID:         696,
 		Date:       "1990-04-05",
 		Duration:   "2h 27m",
+		durationMS: 8831401,
+		TourID:     8,
+		VenueID:    339,
 		Sbd:        true,
 		Remastered: false,
 		Tags: []Tag{
 			{
-				Name:  "SBD",
-				Group: "Audio",
+				ID:       1,
+				Name:     "SBD",
+				Priority: 1,
+				Color:    "#888888",
+				Group:    "Audio",
 			},
 		},
 		VenueName: "J.J. McCabe's",
@@ -913,14 +1016,19 @@ func TestGetShow(t *testing.T) {
 				VenueLocation: "Boulder, CO",
 				Title:         "Possum",
 				Duration:      "6m 48s",
+				durationMS:    408033,
 				SetName:       "Set 1",
 				Tags: []Tag{
 					{
-						Name:  "SBD",
-						Group: "Audio",
+						ID:       1,
+						Name:     "SBD",
+						Priority: 1,
+						Color:    "#888888",
+						Group:    "Audio",
 					},
 				},
-				Mp3: "https://phish.in/audio/000/014/073/14073.mp3",
+				Mp3:           "https://phish.in/audio/000/014/073/14073.mp3",
+				WaveformImage: "https://phish.in/audio/000/014/073/waveform-14073.png",
 			},
 			{
 				ID:            14074,
@@ -929,19 +1037,28 @@ func TestGetShow(t *testing.T) {
 				VenueLocation: "Boulder, CO",
 				Title:         "Ya Mar",
 				Duration:      "7m 7s",
+				durationMS:    427024,
 				SetName:       "Set 1",
 				Tags: []Tag{
 					{
-						Name:  "SBD",
-						Group: "Audio",
+						ID:       1,
+						Name:     "SBD",
+						Priority: 1,
+						Color:    "#888888",
+						Group:    "Audio",
 					},
 					{
-						Name:  "Tease",
-						Group: "Song Content",
-						Notes: "Theme from Bonanza by Ray Evans and\n Jay Livingston",
+						ID:             17,
+						Name:           "Tease",
+						Priority:       15,
+						Color:          "#888888",
+						Group:          "Song Content",
+						Notes:          "Theme from Bonanza by Ray Evans and\n Jay Livingston",
+						StartsAtSecond: 306,
 					},
 				},
-				Mp3: "https://phish.in/audio/000/014/074/14074.mp3",
+				Mp3:           "https://phish.in/audio/000/014/074/14074.mp3",
+				WaveformImage: "https://phish.in/audio/000/014/074/waveform-14074.png",
 			},
 		},
 	}
@@ -991,12 +1108,24 @@ func TestGetVenues(t *testing.T) {
 				Location:   "Johnson, VT",
 				ShowsCount: 2,
 				ShowDates:  []string{"1988-03-11", "1989-04-14"},
+				OtherNames: []string{},
+				Latitude:   44.558803,
+				Longitude:  -72.577842,
+				City:       "Johnson",
+				State:      "VT",
+				Country:    "USA",
 			},
 			{
 				Name:       "The Academy",
 				Location:   "New York, NY",
 				ShowsCount: 1,
 				ShowDates:  []string{"1991-07-15"},
+				OtherNames: []string{},
+				Latitude:   40.783515,
+				Longitude:  -73.958766,
+				City:       "New York",
+				State:      "NY",
+				Country:    "USA",
 			},
 		},
 	}
@@ -1031,6 +1160,12 @@ func TestGetVenue(t *testing.T) {
 		Location:   "New York, NY",
 		ShowsCount: 1,
 		ShowDates:  []string{"1991-07-15"},
+		OtherNames: []string{},
+		Latitude:   40.783515,
+		Longitude:  -73.958766,
+		City:       "New York",
+		State:      "NY",
+		Country:    "USA",
 	}
 	ctx := context.Background()
 	c.Query = query
@@ -1055,16 +1190,21 @@ func TestGetTags(t *testing.T) {
 	c.BaseURL = ts.URL
 	c.HTTPClient = ts.Client()
 	want := TagsOutput{
+		TotalEntries: 19,
+		TotalPages:   1,
+		CurrentPage:  1,
 		Tags: []TagListItemOutput{
 			{
 				Name:        "Costume",
 				Description: "Musical costume sequence",
 				Group:       "Set Content",
+				Color:       "#888888",
 			},
 			{
 				Name:        "Audience",
 				Description: "Contribution from audience during performance",
 				Group:       "Song Content",
+				Color:       "#888888",
 			},
 		},
 	}
@@ -1098,6 +1238,7 @@ func TestGetTag(t *testing.T) {
 		Name:        "Jamcharts",
 		Description: "Phish.net Jam Charts selections (phish.net/jamcharts)",
 		Group:       "Curated Selections",
+		Color:       "#888888",
 		ShowIds:     []int{3},
 		TrackIds:    []int{1, 2},
 	}
@@ -1135,6 +1276,9 @@ func TestGetTours(t *testing.T) {
 						ID:            1324,
 						Date:          "1983-12-02",
 						Duration:      "17m 11s",
+						durationMS:    1031524,
+						TourID:        1,
+						VenueID:       306,
 						Sbd:           true,
 						Remastered:    false,
 						Venue:         VenueOutput{},
@@ -1155,6 +1299,9 @@ func TestGetTours(t *testing.T) {
 						ID:            1334,
 						Date:          "1984-11-03",
 						Duration:      "1h 10m",
+						durationMS:    4214569,
+						TourID:        2,
+						VenueID:       610,
 						Sbd:           false,
 						Remastered:    false,
 						Venue:         VenueOutput{},
@@ -1167,6 +1314,9 @@ func TestGetTours(t *testing.T) {
 						ID:            2,
 						Date:          "1984-12-01",
 						Duration:      "1h 35m",
+						durationMS:    5726850,
+						TourID:        2,
+						VenueID:       467,
 						Sbd:           true,
 						Remastered:    false,
 						Venue:         VenueOutput{},
@@ -1215,6 +1365,9 @@ func TestGetTour(t *testing.T) {
 				ID:            3,
 				Date:          "1985-03-04",
 				Duration:      "40m 14s",
+				durationMS:    2414471,
+				TourID:        3,
+				VenueID:       326,
 				Sbd:           true,
 				Remastered:    false,
 				Venue:         VenueOutput{},
@@ -1308,19 +1461,27 @@ func TestGetSong(t *testing.T) {
 				VenueLocation: "Plainfield, VT",
 				Title:         "David Bowie",
 				Duration:      "10m 19s",
+				durationMS:    619807,
 				SetName:       "Set 2",
 				Tags: []Tag{
 					{
-						Name:  "SBD",
-						Group: "Audio",
+						ID:       1,
+						Name:     "SBD",
+						Priority: 1,
+						Color:    "#888888",
+						Group:    "Audio",
 					},
 					{
-						Name:  "Jamcharts",
-						Group: "Curated Selections",
-						Notes: "Earliest known live version. Jam is played at a slowed tempo initially, but picks up speed and intensity as it develops.",
+						ID:       4,
+						Name:     "Jamcharts",
+						Priority: 4,
+						Color:    "#888888",
+						Group:    "Curated Selections",
+						Notes:    "Earliest known live version. Jam is played at a slowed tempo initially, but picks up speed and intensity as it develops.",
 					},
 				},
-				Mp3: "https://phish.in/audio/000/000/115/115.mp3",
+				Mp3:           "https://phish.in/audio/000/000/115/115.mp3",
+				WaveformImage: "https://phish.in/audio/000/000/115/waveform-115.png",
 			},
 		},
 	}
@@ -1358,9 +1519,11 @@ func TestGetTracks(t *testing.T) {
 				VenueName:     "Stabler Arena, Lehigh University",
 				VenueLocation: "Bethlehem, PA",
 				Duration:      "11m 13s",
+				durationMS:    673672,
 				SetName:       "Set 2",
 				Tags:          []Tag{},
 				Mp3:           "https://phish.in/audio/000/004/270/4270.mp3",
+				WaveformImage: "https://phish.in/audio/000/004/270/waveform-4270.png",
 			},
 			{
 				ID:            6693,
@@ -1369,19 +1532,27 @@ func TestGetTracks(t *testing.T) {
 				VenueName:     "State Theatre",
 				VenueLocation: "Minneapolis, MN",
 				Duration:      "11m 15s",
+				durationMS:    675971,
 				SetName:       "Set 1",
 				Tags: []Tag{
 					{
-						Name:  "SBD",
-						Group: "Audio",
+						ID:       1,
+						Name:     "SBD",
+						Priority: 1,
+						Color:    "#888888",
+						Group:    "Audio",
 					},
 					{
-						Name:  "Jamcharts",
-						Group: "Curated Selections",
-						Notes: "Several minutes of growly, percussive, dissonant, and atypical jamming.",
+						ID:       4,
+						Name:     "Jamcharts",
+						Priority: 4,
+						Color:    "#888888",
+						Group:    "Curated Selections",
+						Notes:    "Several minutes of growly, percussive, dissonant, and atypical jamming.",
 					},
 				},
-				Mp3: "https://phish.in/audio/000/006/693/6693.mp3",
+				Mp3:           "https://phish.in/audio/000/006/693/6693.mp3",
+				WaveformImage: "https://phish.in/audio/000/006/693/waveform-6693.png",
 			},
 		},
 	}
@@ -1418,19 +1589,27 @@ func TestGetTrack(t *testing.T) {
 		VenueName:     "State Theatre",
 		VenueLocation: "Minneapolis, MN",
 		Duration:      "11m 15s",
+		durationMS:    675971,
 		SetName:       "Set 1",
 		Tags: []Tag{
 			{
-				Name:  "SBD",
-				Group: "Audio",
+				ID:       1,
+				Name:     "SBD",
+				Priority: 1,
+				Color:    "#888888",
+				Group:    "Audio",
 			},
 			{
-				Name:  "Jamcharts",
-				Group: "Curated Selections",
-				Notes: "Several minutes of growly, percussive, dissonant, and atypical jamming.",
+				ID:       4,
+				Name:     "Jamcharts",
+				Priority: 4,
+				Color:    "#888888",
+				Group:    "Curated Selections",
+				Notes:    "Several minutes of growly, percussive, dissonant, and atypical jamming.",
 			},
 		},
-		Mp3: "https://phish.in/audio/000/006/693/6693.mp3",
+		Mp3:           "https://phish.in/audio/000/006/693/6693.mp3",
+		WaveformImage: "https://phish.in/audio/000/006/693/waveform-6693.png",
 	}
 	ctx := context.Background()
 	c.Query = query
@@ -1485,6 +1664,9 @@ func TestGetSearch(t *testing.T) {
 					Name:       "Balch Fieldhouse, University of Colorado",
 					Location:   "Boulder, CO",
 					ShowsCount: 2,
+					OtherNames: []string{},
+					Latitude:   40.009472,
+					Longitude:  -105.267949,
 				},
 			},
 		},
@@ -1500,3 +1682,4504 @@ func TestGetSearch(t *testing.T) {
 		t.Errorf("got \n%v \nwant\n%v", got, want)
 	}
 }
+
+func TestJSONEnvelope(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name           string
+		serveFile      string
+		path           string
+		query          string
+		wantPagination bool
+	}{
+		{
+			name:           "list includes pagination",
+			serveFile:      "../testdata/shows.json",
+			path:           "shows",
+			wantPagination: true,
+		},
+		{
+			name:           "detail omits pagination",
+			serveFile:      "../testdata/show.json",
+			path:           "shows",
+			query:          "1990-04-05",
+			wantPagination: false,
+		},
+		{
+			name:           "year details omit pagination",
+			serveFile:      "../testdata/year.json",
+			path:           "years",
+			query:          "1994",
+			wantPagination: false,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ts := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, tc.serveFile)
+				}))
+			defer ts.Close()
+			buf := &bytes.Buffer{}
+			c := NewClient("dummy", buf)
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			c.PrintJSON = true
+			c.Query = tc.query
+			if err := c.run(context.Background(), tc.path); err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := got["data"]; !ok {
+				t.Error("missing data key")
+			}
+			_, ok := got["pagination"]
+			if ok != tc.wantPagination {
+				t.Errorf("got pagination %t want %t", ok, tc.wantPagination)
+			}
+			if !tc.wantPagination {
+				return
+			}
+			var p Pagination
+			if err := json.Unmarshal(got["pagination"], &p); err != nil {
+				t.Fatal(err)
+			}
+			want := Pagination{TotalEntries: 1759, TotalPages: 88, CurrentPage: 1}
+			if p != want {
+				t.Errorf("got %v want %v", p, want)
+			}
+			var shows []ShowOutput
+			if err := json.Unmarshal(got["data"], &shows); err != nil {
+				t.Errorf("data should be an array: %v", err)
+			}
+		})
+	}
+}
+
+func TestSearchMiss(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.ErrOutput = errBuf
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.Query = "zzzz"
+	if err := c.run(context.Background(), "search"); err != nil {
+		t.Fatalf("wanted nil, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wanted no output, got %q", buf.String())
+	}
+	want := "no search results for \"zzzz\"\n"
+	if errBuf.String() != want {
+		t.Errorf("got %q want %q", errBuf.String(), want)
+	}
+	t.Run("other endpoints still error", func(t *testing.T) {
+		errBuf.Reset()
+		c.Query = "zzzz"
+		err := c.run(context.Background(), "songs")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v want %v", err, ErrNotFound)
+		}
+		if errBuf.String() != searchTips {
+			t.Errorf("got %q want search tips", errBuf.String())
+		}
+	})
+	t.Run("raw output just errors", func(t *testing.T) {
+		errBuf.Reset()
+		c.RawOutput = true
+		err := c.run(context.Background(), "songs")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v want %v", err, ErrNotFound)
+		}
+		if buf.Len() != 0 || errBuf.Len() != 0 {
+			t.Errorf("wanted no output, got %q and %q", buf.String(), errBuf.String())
+		}
+	})
+}
+
+func TestFlattenSearch(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/boulder_search.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.Query = "boulder"
+	c.PrintJSON = true
+	c.Flatten = true
+	if err := c.run(context.Background(), "search"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, entity := range got.Data {
+		typ, ok := entity["type"].(string)
+		if !ok {
+			t.Fatalf("missing type in %v", entity)
+		}
+		types = append(types, typ)
+	}
+	want := []string{"track_tag", "venue"}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("got %v want %v", types, want)
+	}
+	if got.Data[1]["name"] != "Balch Fieldhouse, University of Colorado" {
+		t.Errorf("got %v want venue fields alongside the type", got.Data[1])
+	}
+}
+
+func TestSearchJSONL(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/boulder_search.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"search", "-s", "boulder", "-o", "jsonl"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "search"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var sections []string
+	for _, line := range lines {
+		var entity map[string]any
+		if err := json.Unmarshal([]byte(line), &entity); err != nil {
+			t.Fatalf("line %q isn't a json object: %v", line, err)
+		}
+		sections = append(sections, fmt.Sprint(entity["section"]))
+	}
+	want := []string{"track_tag", "venue"}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("got sections %v want %v", sections, want)
+	}
+	if !strings.Contains(lines[1], `"name":"Balch Fieldhouse, University of Colorado"`) {
+		t.Errorf("got %s want venue fields alongside the section", lines[1])
+	}
+	c = NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "-o", "jsonl"}); err == nil {
+		t.Error("wanted an error for jsonl outside of search")
+	}
+}
+
+func TestNestedSets(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.PrintJSON = true
+	c.NestedSets = true
+	c.Query = "1990-04-05"
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Data["tracks"]; ok {
+		t.Error("nested output shouldn't have a flat tracks array")
+	}
+	var sets []SetOutput
+	if err := json.Unmarshal(got.Data["sets"], &sets); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name   string
+		tracks int
+		first  string
+	}{
+		{name: "Set 1", tracks: 9, first: "Possum"},
+		{name: "Set 2", tracks: 13, first: "Reba"},
+		{name: "Encore", tracks: 1, first: "Golgi Apparatus"},
+	}
+	if len(sets) != len(want) {
+		t.Fatalf("got %d sets want %d", len(sets), len(want))
+	}
+	for i, w := range want {
+		if sets[i].Name != w.name {
+			t.Errorf("got %q want %q", sets[i].Name, w.name)
+		}
+		if len(sets[i].Tracks) != w.tracks {
+			t.Errorf("%s: got %d tracks want %d", w.name, len(sets[i].Tracks), w.tracks)
+		}
+		if sets[i].Tracks[0].Title != w.first {
+			t.Errorf("%s: got %q want %q", w.name, sets[i].Tracks[0].Title, w.first)
+		}
+	}
+}
+
+func TestGetShowsByID(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			id := strings.TrimPrefix(r.URL.Path, "/shows/")
+			fmt.Fprintf(w, `{"data": {"id": %s}}`, id)
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.Parallel = 3
+	ids := make([]int, 0, 20)
+	for i := 1; i <= 20; i++ {
+		ids = append(ids, i)
+	}
+	got, err := c.getShowsByID(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Shows) != len(ids) {
+		t.Fatalf("got %d shows want %d", len(got.Shows), len(ids))
+	}
+	for i, s := range got.Shows {
+		if s.ID != ids[i] {
+			t.Errorf("got id %d at index %d want %d", s.ID, i, ids[i])
+		}
+	}
+	if m := atomic.LoadInt32(&maxInFlight); m > int32(c.Parallel) {
+		t.Errorf("got %d concurrent requests, limit is %d", m, c.Parallel)
+	}
+}
+
+func TestParallelIsSharedAcrossNestedWork(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			id := strings.TrimPrefix(r.URL.Path, "/shows/")
+			fmt.Fprintf(w, `{"data": {"id": %s}}`, id)
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.Parallel = 2
+	c.ErrGroup.SetLimit(c.Parallel)
+	// each lookup fans out on its own, as a show's downloads do inside
+	// the client's errgroup
+	for i := 0; i < 3; i++ {
+		ids := []int{i*10 + 1, i*10 + 2, i*10 + 3, i*10 + 4}
+		c.ErrGroup.Go(func() error {
+			_, err := c.getShowsByID(context.Background(), ids)
+			return err
+		})
+	}
+	if err := c.ErrGroup.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if m := atomic.LoadInt32(&maxInFlight); m > int32(c.Parallel) {
+		t.Errorf("got %d concurrent requests, limit is %d", m, c.Parallel)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		since   string
+		want    time.Time
+		wantErr bool
+	}{
+		{since: "2023-12-31", want: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{since: "24h", want: now.Add(-24 * time.Hour)},
+		{since: "7d", want: now.AddDate(0, 0, -7)},
+		{since: "2w", want: now.AddDate(0, 0, -14)},
+		{since: "7y", wantErr: true},
+		{since: "d", wantErr: true},
+		{since: "12-31", wantErr: true},
+	}
+	for _, tc := range tt {
+		got, err := parseSince(tc.since, now)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: wanted error, got nil", tc.since)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: wanted nil, got %v", tc.since, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: got %v want %v", tc.since, got, tc.want)
+		}
+	}
+}
+
+func TestGetShowsSince(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows_on_day_of_year.json")
+		}))
+	defer ts.Close()
+	tt := []struct {
+		name  string
+		since string
+		want  []int
+	}{
+		{name: "absolute keeps shows updated on the date", since: "2018-12-21", want: []int{570, 499}},
+		{name: "absolute drops older shows", since: "2018-12-22", want: []int{}},
+		{name: "relative keeps recent shows", since: "1d", want: []int{570}},
+	}
+	for _, tc := range tt {
+		c := NewClient("dummy", io.Discard)
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		c.Now = func() time.Time { return time.Date(2018, 12, 22, 8, 10, 19, 0, time.UTC) }
+		if err := c.fromArgs([]string{"shows-on-day-of-year", "-s", "10-31", "--since", tc.since}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.getShows(context.Background(), c.FormatURL("shows-on-day-of-year"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int, 0, len(got.Shows))
+		for _, s := range got.Shows {
+			ids = append(ids, s.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.want) {
+			t.Errorf("%s: got %v want %v", tc.name, ids, tc.want)
+		}
+	}
+}
+
+// newDownloadServer serves the fixture with its mp3 urls pointed back at
+// the test server, which responds to those with some fake audio.
+func newDownloadServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	b, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/audio/") {
+				fmt.Fprintf(w, "audio for %s", r.URL.Path)
+				return
+			}
+			_, _ = w.Write(bytes.ReplaceAll(b, []byte("https://phish.in/audio/"), []byte(ts.URL+"/audio/")))
+		}))
+	return ts
+}
+
+func TestDownloadByYear(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		fixture string
+		args    []string
+		want    string
+	}{
+		{"../testdata/show.json", []string{"shows", "-s", "1990-04-05"}, filepath.Join("1990", "1990-04-05", "1-possum.mp3")},
+		{"../testdata/track.json", []string{"tracks", "-s", "6693"}, filepath.Join("1993", "1993-04-09", "stash.mp3")},
+	}
+	for _, tc := range testCases {
+		ts := newDownloadServer(t, tc.fixture)
+		defer ts.Close()
+		dir := t.TempDir()
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs(append(tc.args, "-d", "--output-dir", dir, "--by-year")); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), tc.args[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.ErrGroup.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, tc.want)); err != nil {
+			t.Errorf("%v: wanted %s: %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+// TestDownloadSummary checks that the summary is all that's printed, since
+// the per-file progress goes to ErrOutput along with it.
+func TestDownloadSummary(t *testing.T) {
+	t.Parallel()
+	ts := newDownloadServer(t, "../testdata/show.json")
+	defer ts.Close()
+	errOut := &bytes.Buffer{}
+	c := NewClient("dummy", io.Discard)
+	c.ErrOutput = errOut
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-d", "--output-dir", t.TempDir(), "--download-summary"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.waitForDownloads(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// progress goes to ErrOutput too, so this also checks there was none
+	want := regexp.MustCompile(`^downloaded 23 files \([^)]+\) in \S+, 0 failed\n$`)
+	if got := errOut.String(); !want.MatchString(got) {
+		t.Errorf("got %q, wanted a single summary line", got)
+	}
+
+	t.Run("aborted and skipped downloads are counted", func(t *testing.T) {
+		dir := t.TempDir()
+		c := NewClient("dummy", io.Discard)
+		c.ErrOutput = io.Discard
+		c.HTTPClient = ts.Client()
+		c.SkipExisting = true
+		url := ts.URL + "/audio/reba.mp3"
+		if _, err := c.DownloadTrack(context.Background(), url, "reba.mp3", dir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.DownloadTrack(context.Background(), url, "reba.mp3", dir); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := c.DownloadTrack(ctx, url, "other.mp3", dir); err == nil {
+			t.Fatal("wanted an error for a canceled download")
+		}
+		got := c.downloads.summary(time.Second)
+		if want := "downloaded 1 files (25  B) in 1s, 1 failed, 1 already downloaded"; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("tracks the manifest already lists are counted", func(t *testing.T) {
+		dir := t.TempDir()
+		for i := 0; i < 2; i++ {
+			c := NewClient("dummy", io.Discard)
+			c.ErrOutput = io.Discard
+			if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-d", "--output-dir", dir, "--skip-existing", "--manifest"}); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			if err := c.run(context.Background(), "shows"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.waitForDownloads(time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			if i == 0 {
+				continue
+			}
+			got := c.downloads.summary(time.Second)
+			if want := "downloaded 0 files (0 B) in 1s, 0 failed, 23 already downloaded"; got != want {
+				t.Errorf("got %q want %q", got, want)
+			}
+		}
+	})
+}
+
+func TestDownloadShowManifest(t *testing.T) {
+	t.Parallel()
+	ts := newDownloadServer(t, "../testdata/show.json")
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.DownloadDir = t.TempDir()
+	c.Download = true
+	c.Manifest = true
+	c.Query = "1990-04-05"
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ErrGroup.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(c.DownloadDir, "1990-04-05", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Manifest
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Show.ID != 696 || got.Show.Date != "1990-04-05" {
+		t.Errorf("got show %d on %s want 696 on 1990-04-05", got.Show.ID, got.Show.Date)
+	}
+	if len(got.Tracks) != 23 {
+		t.Fatalf("got %d tracks want 23", len(got.Tracks))
+	}
+	audio := "audio for /audio/000/014/073/14073.mp3"
+	sum := sha256.Sum256([]byte(audio))
+	wantFirst := ManifestTrack{
+		FileName: "1-possum.mp3",
+		Mp3:      ts.URL + "/audio/000/014/073/14073.mp3",
+		Duration: "6m 48s",
+		Size:     int64(len(audio)),
+		SHA256:   hex.EncodeToString(sum[:]),
+	}
+	if got.Tracks[0] != wantFirst {
+		t.Errorf("got %v want %v", got.Tracks[0], wantFirst)
+	}
+	for _, track := range got.Tracks {
+		if _, err := os.Stat(filepath.Join(c.DownloadDir, "1990-04-05", track.FileName)); err != nil {
+			t.Errorf("manifest lists %s but it wasn't downloaded: %v", track.FileName, err)
+		}
+	}
+}
+
+func TestDownloadTrack(t *testing.T) {
+	t.Parallel()
+	content := "not really an mp3"
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, content)
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.HTTPClient = ts.Client()
+	dir := t.TempDir()
+	got, err := c.DownloadTrack(context.Background(), ts.URL+"/stash.mp3", "stash.mp3", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256 of "not really an mp3"
+	want := DownloadedFile{
+		Path:   filepath.Join(dir, "stash.mp3"),
+		Size:   int64(len(content)),
+		SHA256: "b8cc025e320c78d4b497ea69c988929294ea0318bd7fdc3a66e126cc8941de4c",
+	}
+	if got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	b, err := os.ReadFile(got.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != content {
+		t.Errorf("got %q want %q", b, content)
+	}
+}
+
+func TestDownloadTrackRetries(t *testing.T) {
+	t.Parallel()
+	content := "not really an mp3"
+	var attempts int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&attempts, 1)
+			if r.URL.Path == "/missing.mp3" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, content)
+		}))
+	defer ts.Close()
+	var waits []time.Duration
+	newClient := func() *Client {
+		c := NewClient("dummy", io.Discard)
+		c.HTTPClient = ts.Client()
+		c.Retries = 2
+		// each wait is over at once
+		c.After = func(d time.Duration) <-chan time.Time {
+			waits = append(waits, d)
+			ch := make(chan time.Time, 1)
+			ch <- c.Now()
+			return ch
+		}
+		return c
+	}
+
+	t.Run("recovers after a retry", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		waits = nil
+		dir := t.TempDir()
+		got, err := newClient().DownloadTrack(context.Background(), ts.URL+"/stash.mp3", "stash.mp3", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Size != int64(len(content)) {
+			t.Errorf("got size %d want %d", got.Size, len(content))
+		}
+		b, err := os.ReadFile(got.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("got %q want %q", b, content)
+		}
+		if got := atomic.LoadInt32(&attempts); got != 2 {
+			t.Errorf("got %d attempts want 2", got)
+		}
+		if want := []time.Duration{defaultRetryWait}; !reflect.DeepEqual(waits, want) {
+			t.Errorf("got waits %v want %v", waits, want)
+		}
+	})
+	t.Run("not found isn't retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		if _, err := newClient().DownloadTrack(context.Background(), ts.URL+"/missing.mp3", "missing.mp3", t.TempDir()); err == nil {
+			t.Fatal("wanted an error")
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("got %d attempts want 1", got)
+		}
+	})
+}
+
+func TestVerifyManifest(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "1-possum.mp3", content: "possum audio"},
+		{name: "2-ya-mar.mp3", content: "ya mar audio"},
+		{name: "3-david-bowie.mp3", content: "david bowie audio"},
+		{name: "4-carolina.mp3", content: "carolina audio"},
+	}
+	m := Manifest{}
+	for _, f := range files {
+		sum := sha256.Sum256([]byte(f.content))
+		m.Tracks = append(m.Tracks, ManifestTrack{
+			FileName: f.name,
+			Size:     int64(len(f.content)),
+			SHA256:   hex.EncodeToString(sum[:]),
+		})
+		if err := os.WriteFile(filepath.Join(dir, f.name), []byte(f.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeManifest(dir, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "4-carolina.mp3")); err != nil {
+		t.Fatal(err)
+	}
+	// same size, different content
+	if err := os.WriteFile(filepath.Join(dir, "2-ya-mar.mp3"), []byte("ya mar AUDIO"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// truncated
+	if err := os.WriteFile(filepath.Join(dir, "3-david-bowie.mp3"), []byte("david"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := verifyManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VerifyOutput{
+		Dir: dir,
+		Results: []VerifyResult{
+			{FileName: "1-possum.mp3", Status: verifyOK},
+			{FileName: "2-ya-mar.mp3", Status: verifyChecksum},
+			{FileName: "3-david-bowie.mp3", Status: verifySize},
+			{FileName: "4-carolina.mp3", Status: verifyMissing},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got.Failures() != 3 {
+		t.Errorf("got %d failures want 3", got.Failures())
+	}
+	t.Run("missing manifest errors", func(t *testing.T) {
+		if _, err := verifyManifest(t.TempDir()); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestJSONIndent(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/simple_eras.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"eras", "-o", "json", "--json-indent", "4"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "eras.json.indent4.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+	t.Run("tab and invalid values", func(t *testing.T) {
+		indent, err := parseJSONIndent("tab")
+		if err != nil || indent != "\t" {
+			t.Errorf("got %q, %v want tab", indent, err)
+		}
+		if _, err := parseJSONIndent("wide"); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestGroupSongsByArtist(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/covers.json")
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", os.Stdout)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	songs, err := c.getSongs(context.Background(), c.FormatURL("songs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := groupSongsByArtist(songs.Songs)
+	want := SongsByArtistOutput{
+		Artists: []ArtistSongs{
+			{Artist: "Phish", Count: 2, Songs: []string{"Billy Breathes", "David Bowie"}},
+			{Artist: "Talking Heads", Count: 2, Songs: []string{"Cities", "Crosseyed and Painless"}},
+			{Artist: "Arctic Monkeys", Count: 1, Songs: []string{"Arc"}},
+			{Artist: "The Velvet Underground", Count: 1, Songs: []string{"Rock and Roll"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got \n%v \nwant\n%v", got, want)
+	}
+}
+
+func TestAPIKeySources(t *testing.T) {
+	t.Parallel()
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("file-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "env", args: []string{"eras"}, want: "env-key"},
+		{name: "file over env", args: []string{"eras", "--api-key-file", keyFile}, want: "file-key"},
+		{name: "flag over env", args: []string{"eras", "--api-key", "flag-key"}, want: "flag-key"},
+		{name: "flag over file", args: []string{"eras", "--api-key-file", keyFile, "--api-key", "flag-key"}, want: "flag-key"},
+	}
+	for _, tc := range tt {
+		c := NewClient("env-key", io.Discard)
+		if err := c.fromArgs(tc.args); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if c.APIKey != tc.want {
+			t.Errorf("%s: got %q want %q", tc.name, c.APIKey, tc.want)
+		}
+	}
+	t.Run("missing file errors", func(t *testing.T) {
+		c := NewClient("env-key", io.Discard)
+		if err := c.fromArgs([]string{"eras", "--api-key-file", filepath.Join(t.TempDir(), "nope")}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestGetToursAudit(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/tours" {
+				t.Fatalf("wrong url: %s", r.URL.Path)
+			}
+			http.ServeFile(w, r, "../testdata/tours_mismatch.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"audit", "tours"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	got, err := c.getToursAudit(context.Background(), c.FormatURL("tours"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TourAuditOutput{
+		Checked: 2,
+		Mismatches: []TourAudit{
+			{Name: "1984 Tour", Slug: "1984-tour", ShowsCount: 3, ShowsPresent: 2},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	t.Run("audit needs a subject", func(t *testing.T) {
+		if err := c.fromArgs([]string{"audit"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+		if err := c.fromArgs([]string{"audit", "venues"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// requests sent through a proxy carry the full target url
+			if r.URL.Host != "phish.example" {
+				t.Errorf("got host %q want phish.example", r.URL.Host)
+			}
+			atomic.AddInt32(&proxied, 1)
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer proxy.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"eras", "--proxy", proxy.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTPClient == http.DefaultClient {
+		t.Fatal("proxy should not modify the default client")
+	}
+	c.BaseURL = "http://phish.example/api/v1"
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&proxied) != 1 {
+		t.Errorf("got %d proxied requests want 1", proxied)
+	}
+	t.Run("invalid proxy errors", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"eras", "--proxy", "localhost"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestInsecure(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	t.Run("self-signed cert fails by default", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"eras", "--base-url", ts.URL}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.run(context.Background(), "eras"); err == nil {
+			t.Error("wanted tls error, got nil")
+		}
+	})
+	t.Run("insecure skips verification", func(t *testing.T) {
+		errBuf := &bytes.Buffer{}
+		c := NewClient("dummy", io.Discard)
+		c.ErrOutput = errBuf
+		if err := c.fromArgs([]string{"eras", "--base-url", ts.URL, "--insecure"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.run(context.Background(), "eras"); err != nil {
+			t.Errorf("wanted nil, got %v", err)
+		}
+		if !strings.Contains(errBuf.String(), "level=WARN") {
+			t.Errorf("wanted a warning, got %q", errBuf.String())
+		}
+		if cfg := http.DefaultTransport.(*http.Transport).TLSClientConfig; cfg != nil && cfg.InsecureSkipVerify {
+			t.Error("insecure should not modify the default transport")
+		}
+	})
+	t.Run("insecure requires base url", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		c.ErrOutput = io.Discard
+		if err := c.fromArgs([]string{"eras", "--insecure"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestSongTrackPage(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/song_tweezer.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"songs", "-s", "tweezer", "--track-page", "2", "--track-per-page", "2", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "songs"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Data SongOutput `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Data.Tracks) != 1 || got.Data.Tracks[0].ID != 17462 {
+		t.Errorf("wanted only the third track, got %+v", got.Data.Tracks)
+	}
+	want := &Pagination{TotalEntries: 3, TotalPages: 2, CurrentPage: 2}
+	if !reflect.DeepEqual(got.Data.TracksPage, want) {
+		t.Errorf("got page %+v want %+v", got.Data.TracksPage, want)
+	}
+
+	c.Output = io.Discard
+	c.TrackPage = 3
+	if err := c.run(context.Background(), "songs"); err == nil {
+		t.Error("wanted an error for a page past the last")
+	}
+	for _, args := range [][]string{
+		{"songs", "--track-page", "2"},
+		{"shows", "-s", "1990-04-05", "--track-per-page", "5"},
+		{"songs", "-s", "tweezer", "--track-page", "-1"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestSongsBreakdown(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	var gotQuery string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			gotQuery = r.URL.RawQuery
+			http.ServeFile(w, r, "../testdata/songs_breakdown.json")
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"songs", "--breakdown", "--all"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	results, err := c.Result(context.Background(), "songs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SongsBreakdownOutput{
+		Originals: 3,
+		Covers:    3,
+		TopCoveredArtists: []ArtistCount{
+			{Artist: "The Beatles", Count: 2},
+			{Artist: "The Velvet Underground", Count: 1},
+		},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("got %+v want %+v", results, want)
+	}
+	if requests != 1 || gotQuery != "per_page=100&page=1" {
+		t.Errorf("wanted every song fetched in one page, got %d requests, the last with query %q", requests, gotQuery)
+	}
+
+	if err := NewClient("dummy", io.Discard).fromArgs([]string{"songs", "-s", "tweezer", "--breakdown"}); err == nil {
+		t.Error("wanted an error for breakdown of a song")
+	}
+}
+
+func TestMaxTracks(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/songs/tweezer":     "../testdata/song_tweezer.json",
+		"/songs/david-bowie": "../testdata/song.json",
+		"/shows/1990-04-05":  "../testdata/show.json",
+		"/eras":              "../testdata/eras.json",
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, files[r.URL.Path])
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		args    []string
+		want    string
+		notWant string
+	}{
+		{[]string{"songs", "-s", "tweezer", "--max-tracks", "2"}, "(showing 2 of 3 tracks)", "17462"},
+		{[]string{"shows", "-s", "1990-04-05", "--max-tracks", "5"}, "(showing 5 of 23 tracks)", "The Lizards"},
+		{[]string{"shows", "-s", "1990-04-05", "-v", "--max-tracks", "5"}, "(showing 5 of 23 tracks)", "The Lizards"},
+		// it has only the one track, so there's nothing to cap
+		{[]string{"songs", "-s", "david-bowie", "--max-tracks", "1"}, "Tracks", "showing"},
+	}
+	for _, tc := range testCases {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), tc.args[0]); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		got := buf.String()
+		if !strings.Contains(got, tc.want) {
+			t.Errorf("%v: wanted %q in\n%s", tc.args, tc.want, got)
+		}
+		if strings.Contains(got, tc.notWant) {
+			t.Errorf("%v: didn't want %q in\n%s", tc.args, tc.notWant, got)
+		}
+	}
+}
+
+func TestDownloadSong(t *testing.T) {
+	t.Parallel()
+	ts := newDownloadServer(t, "../testdata/song_tweezer.json")
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.DownloadDir = t.TempDir()
+	c.Download = true
+	c.Query = "tweezer"
+	if err := c.run(context.Background(), "songs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ErrGroup.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"1993-05-07.mp3":    "audio for /audio/000/011/682/11682.mp3",
+		"1994-10-31.mp3":    "audio for /audio/000/017/459/17459.mp3",
+		"1994-10-31-27.mp3": "audio for /audio/000/017/462/17462.mp3",
+	}
+	entries, err := os.ReadDir(filepath.Join(c.DownloadDir, "tweezer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("got %d files want %d", len(entries), len(want))
+	}
+	for name, audio := range want {
+		b, err := os.ReadFile(filepath.Join(c.DownloadDir, "tweezer", name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != audio {
+			t.Errorf("%s: got %q want %q", name, b, audio)
+		}
+	}
+}
+
+func TestSkipExisting(t *testing.T) {
+	t.Parallel()
+	b, err := os.ReadFile("../testdata/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/audio/") {
+				mu.Lock()
+				requests[r.Method+" "+r.URL.Path]++
+				mu.Unlock()
+				fmt.Fprintf(w, "audio for %s", r.URL.Path)
+				return
+			}
+			_, _ = w.Write(bytes.ReplaceAll(b, []byte("https://phish.in/audio/"), []byte(ts.URL+"/audio/")))
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-d", "--skip-existing", "--manifest"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.DownloadDir = t.TempDir()
+	dir := filepath.Join(c.DownloadDir, "1990-04-05")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// one complete file, and one cut short
+	if err := os.WriteFile(filepath.Join(dir, "1-possum.mp3"), []byte("audio for /audio/000/014/073/14073.mp3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "2-ya-mar.mp3"), []byte("audio for"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	download := func() {
+		t.Helper()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.ErrGroup.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	download()
+	if got := requests["GET /audio/000/014/073/14073.mp3"]; got != 0 {
+		t.Errorf("complete file downloaded %d times, want it skipped", got)
+	}
+	if got := requests["GET /audio/000/014/074/14074.mp3"]; got != 1 {
+		t.Errorf("partial file downloaded %d times want 1", got)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "2-ya-mar.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "audio for /audio/000/014/074/14074.mp3"; string(got) != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	o, err := verifyManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Failures() != 0 {
+		t.Errorf("got %d manifest failures want 0", o.Failures())
+	}
+
+	// with a manifest in place, nothing needs a request
+	requests = make(map[string]int)
+	c.Output = io.Discard
+	download()
+	if len(requests) != 0 {
+		t.Errorf("got requests %v want none", requests)
+	}
+}
+
+func TestDownloadShowSet(t *testing.T) {
+	t.Parallel()
+	ts := newDownloadServer(t, "../testdata/show.json")
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.DownloadDir = t.TempDir()
+	c.Download = true
+	c.Query = "1990-04-05"
+	c.Set = "set 2"
+	got, err := c.getShow(context.Background(), c.FormatURL("shows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ErrGroup.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tracks) != 13 {
+		t.Errorf("got %d tracks want 13", len(got.Tracks))
+	}
+	entries, err := os.ReadDir(filepath.Join(c.DownloadDir, "1990-04-05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 13 {
+		t.Fatalf("got %d files want 13", len(entries))
+	}
+	for _, e := range entries {
+		// set 2 runs from track 10 (reba) through 22 (contact)
+		var n int
+		if _, err := fmt.Sscanf(e.Name(), "%d-", &n); err != nil || n < 10 || n > 22 {
+			t.Errorf("%s isn't in set 2", e.Name())
+		}
+	}
+	t.Run("unknown set errors", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		c.Query = "1990-04-05"
+		c.Set = "Set 3"
+		if _, err := c.getShow(context.Background(), c.FormatURL("shows")); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+	t.Run("the manifest only has the set", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		c.DownloadDir = t.TempDir()
+		c.Download = true
+		c.Manifest = true
+		c.Query = "1990-04-05"
+		c.Set = "set 2"
+		if _, err := c.getShow(context.Background(), c.FormatURL("shows")); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.ErrGroup.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(c.DownloadDir, "1990-04-05", manifestFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Manifest
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Tracks) != 13 || len(got.Show.Tracks) != 13 {
+			t.Fatalf("got %d tracks and %d show tracks, want 13 of each", len(got.Tracks), len(got.Show.Tracks))
+		}
+		for _, track := range got.Show.Tracks {
+			if !strings.EqualFold(track.SetName, "set 2") {
+				t.Errorf("manifest show has %s from %s", track.Title, track.SetName)
+			}
+		}
+	})
+}
+
+func TestTheme(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	render := func(t *testing.T, args ...string) string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(append([]string{"shows"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if got := render(t); strings.Contains(got, "\x1b[") {
+		t.Errorf("auto color should be off when not writing to a terminal, got %q", got)
+	}
+	def := render(t, "--color", "always")
+	if !strings.Contains(def, ansiYellow+"Date:"+ansiReset) {
+		t.Errorf("default theme should color dates yellow, got %q", def)
+	}
+	ocean := render(t, "--color", "always", "--theme", "ocean")
+	if !strings.Contains(ocean, ansiBlue+"Date:"+ansiReset) {
+		t.Errorf("ocean theme should color dates blue, got %q", ocean)
+	}
+	if !strings.Contains(ocean, ansiMagenta) || strings.Contains(ocean, ansiGreen) {
+		t.Errorf("ocean theme should color durations magenta, got %q", ocean)
+	}
+	t.Run("unknown theme errors", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "--theme", "plaid"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestGetIncompleteReport(t *testing.T) {
+	t.Parallel()
+	var paths []string
+	var mu sync.Mutex
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			paths = append(paths, r.URL.RequestURI())
+			mu.Unlock()
+			http.ServeFile(w, r, "../testdata/incomplete.json")
+		}))
+	defer ts.Close()
+	want := IncompleteReportOutput{
+		Checked: 3,
+		Shows: []IncompleteShow{
+			{Date: "1994-04-05", VenueName: "Beacon Theatre", VenueLocation: "New York, NY", Reason: "incomplete"},
+			{Date: "1994-04-06", VenueName: "The Warfield", VenueLocation: "San Francisco, CA", Reason: "no tracks"},
+		},
+	}
+	tt := []struct {
+		name string
+		args []string
+		path string
+	}{
+		{name: "year", args: []string{"report", "incomplete", "1994"}, path: "/years/1994"},
+		{name: "all", args: []string{"report", "incomplete", "--all"}, path: "/shows?per_page=100&page=1"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mu.Lock()
+			paths = nil
+			mu.Unlock()
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			got, err := c.getIncompleteReport(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+			if !reflect.DeepEqual(paths, []string{tc.path}) {
+				t.Errorf("got requests %v want %v", paths, []string{tc.path})
+			}
+		})
+	}
+	t.Run("needs a year or --all", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"report", "incomplete"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+// not parallel, since it swaps out DefaultClient
+func TestDefaultClientHelpers(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer helper-key" {
+				t.Errorf("got auth %q want the helper's key", r.Header.Get("Authorization"))
+			}
+			switch r.URL.Path {
+			case "/shows":
+				http.ServeFile(w, r, "../testdata/shows.json")
+			case "/shows/1994-04-04":
+				http.ServeFile(w, r, "../testdata/show.json")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer ts.Close()
+	orig := DefaultClient
+	defer func() { DefaultClient = orig }()
+	DefaultClient = NewClient("", io.Discard)
+	DefaultClient.BaseURL = ts.URL
+	DefaultClient.HTTPClient = ts.Client()
+	ctx := context.Background()
+
+	shows, err := GetShows(ctx, "helper-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shows.TotalEntries != 1759 || len(shows.Shows) != 1 {
+		t.Errorf("got %d entries and %d shows, want 1759 and 1", shows.TotalEntries, len(shows.Shows))
+	}
+	show, err := GetShow(ctx, "helper-key", "1994-04-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(show.Tracks) == 0 {
+		t.Error("wanted show tracks, got none")
+	}
+	if DefaultClient.APIKey != "" {
+		t.Errorf("helpers shouldn't modify DefaultClient, got key %q", DefaultClient.APIKey)
+	}
+	if _, err := GetSong(ctx, "helper-key", "harry-hood"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Cache", "HIT")
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	errBuf := &bytes.Buffer{}
+	c := NewClient("super-secret-key", io.Discard)
+	c.ErrOutput = errBuf
+	if err := c.fromArgs([]string{"eras", "--trace"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	got := errBuf.String()
+	if strings.Contains(got, "super-secret-key") {
+		t.Errorf("trace leaked the api key: %q", got)
+	}
+	for _, want := range []string{
+		"> GET " + ts.URL + "/eras\n",
+		"> Authorization: Bearer [REDACTED]\n",
+		"< HTTP/1.1 200 OK\n",
+		"< X-Cache: HIT\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wanted %q in trace, got %q", want, got)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/songs/david-bowie", "/songs/979":
+				http.ServeFile(w, r, "../testdata/song.json")
+			case "/venues/the-academy", "/venues/11":
+				http.ServeFile(w, r, "../testdata/venue.json")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer ts.Close()
+	tt := []struct {
+		name string
+		args []string
+		want ResolveOutput
+	}{
+		{
+			name: "song slug to id",
+			args: []string{"resolve", "songs", "david-bowie"},
+			want: ResolveOutput{Entity: "songs", ID: 979, Slug: "david-bowie", Name: "David Bowie"},
+		},
+		{
+			name: "song id to slug",
+			args: []string{"resolve", "songs", "979"},
+			want: ResolveOutput{Entity: "songs", ID: 979, Slug: "david-bowie", Name: "David Bowie"},
+		},
+		{
+			name: "venue id to slug",
+			args: []string{"resolve", "venues", "11"},
+			want: ResolveOutput{Entity: "venues", ID: 11, Slug: "the-academy", Name: "The Academy"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			got, err := c.resolve(context.Background(), c.Args[0], c.Args[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v want %+v", got, tc.want)
+			}
+		})
+	}
+	t.Run("shows can't be resolved", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"resolve", "shows", "1994-04-04"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/eras":
+				http.ServeFile(w, r, "../testdata/eras.json")
+			case "/eras/1.0":
+				http.ServeFile(w, r, "../testdata/era.json")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer ts.Close()
+	dir := t.TempDir()
+	batch := filepath.Join(dir, "commands.txt")
+	commands := "# eras, then one era\neras\n\nphishin eras -s \"1.0\" -o json\n"
+	if err := os.WriteFile(batch, []byte(commands), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.runBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	outputs := strings.Split(buf.String(), batchSeparator+"\n")
+	if len(outputs) != 2 {
+		t.Fatalf("got %d outputs want 2: %q", len(outputs), buf.String())
+	}
+	if !strings.HasPrefix(outputs[0], "Eras\n") {
+		t.Errorf("wanted the eras list first, got %q", outputs[0])
+	}
+	if !json.Valid([]byte(outputs[1])) {
+		t.Errorf("wanted json for the second command, got %q", outputs[1])
+	}
+}
+
+func TestRunBatchLinesDontShareFlags(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var auths []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			auths = append(auths, r.Header.Get("Authorization"))
+			mu.Unlock()
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	batch := filepath.Join(t.TempDir(), "commands.txt")
+	commands := "shows --color always --api-key other\nshows\n"
+	if err := os.WriteFile(batch, []byte(commands), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.runBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	outputs := strings.Split(buf.String(), batchSeparator+"\n")
+	if len(outputs) != 2 {
+		t.Fatalf("got %d outputs want 2: %q", len(outputs), buf.String())
+	}
+	if !strings.Contains(outputs[0], "\x1b[") {
+		t.Errorf("wanted color on the first line, got %q", outputs[0])
+	}
+	if strings.Contains(outputs[1], "\x1b[") {
+		t.Errorf("the second line kept the first line's color: %q", outputs[1])
+	}
+	if want := []string{"Bearer other", "Bearer dummy"}; !reflect.DeepEqual(auths, want) {
+		t.Errorf("got authorization %q want %q", auths, want)
+	}
+
+	t.Run("pager isn't per line", func(t *testing.T) {
+		batch := filepath.Join(t.TempDir(), "commands.txt")
+		if err := os.WriteFile(batch, []byte("shows --pager\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := NewClient("dummy", io.Discard).runBatch(context.Background(), batch)
+		if err == nil || !strings.Contains(err.Error(), "--pager") {
+			t.Errorf("got %v, wanted an error for --pager in a batch", err)
+		}
+	})
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	t.Parallel()
+	got, err := splitCommandLine(`songs -s "harry hood"  -o json 'a b'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"songs", "-s", "harry hood", "-o", "json", "a b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if _, err := splitCommandLine(`songs -s "harry`); err == nil {
+		t.Error("wanted error for unterminated quote, got nil")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "--summary"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "shows.summary.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+	t.Run("without pagination", func(t *testing.T) {
+		got := summarize(1, "show", nil)
+		if got != "1 show" {
+			t.Errorf("got %q want %q", got, "1 show")
+		}
+	})
+}
+
+func TestParseSortParams(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name string
+		dir  string
+		attr string
+		want []string
+	}{
+		{name: "date defaults to desc", attr: "date", want: []string{"sort_dir=desc", "sort_attr=date"}},
+		{name: "likes defaults to desc", attr: "likes_count", want: []string{"sort_dir=desc", "sort_attr=likes_count"}},
+		{name: "name defaults to asc", attr: "name", want: []string{"sort_dir=asc", "sort_attr=name"}},
+		{name: "unknown attr is left to the server", attr: "position", want: []string{"sort_attr=position"}},
+		{name: "explicit direction wins", dir: "asc", attr: "date", want: []string{"sort_dir=asc", "sort_attr=date"}},
+		{name: "direction alone", dir: "desc", want: []string{"sort_dir=desc"}},
+		{name: "neither", want: nil},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			c.parseSortParams(tc.dir, tc.attr)
+			if !reflect.DeepEqual(c.Parameters, tc.want) {
+				t.Errorf("got %v want %v", c.Parameters, tc.want)
+			}
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		name      string
+		serveFile string
+		args      []string
+		golden    string
+	}{
+		{name: "shows", serveFile: "../testdata/shows.json", args: []string{"shows", "--links"}, golden: "shows.links.golden"},
+		{name: "show", serveFile: "../testdata/show.json", args: []string{"shows", "-s", "1994-04-04", "--links"}, golden: "show.links.golden"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, tc.serveFile)
+				}))
+			defer ts.Close()
+			buf := &bytes.Buffer{}
+			c := NewClient("dummy", buf)
+			if err := c.fromArgs(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			if err := c.run(context.Background(), tc.args[0]); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			want := getGoldenValue(t, tc.golden, got, *updateGolden)
+			if got != want {
+				t.Errorf("got\n%s want\n%s", got, want)
+			}
+		})
+	}
+	t.Run("invalid date has no link", func(t *testing.T) {
+		if got := showLink("1997-11"); got != "" {
+			t.Errorf("got %q want no link", got)
+		}
+	})
+}
+
+func TestColumns(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-v", "--columns", "date, venue,id,sbd"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	wantHeader := "Date:       Venue:         ID:  Soundboard:"
+	if lines[0] != wantHeader {
+		t.Errorf("got header %q want %q", lines[0], wantHeader)
+	}
+	wantRow := "1990-04-05  J.J. McCabe's  696  yes"
+	if lines[1] != wantRow {
+		t.Errorf("got row %q want %q", lines[1], wantRow)
+	}
+	t.Run("unknown column errors", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "--columns", "date,tempo"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestConstrainedRandomShow(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var years []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/eras/1.0":
+				http.ServeFile(w, r, "../testdata/era.json")
+			default:
+				mu.Lock()
+				years = append(years, strings.TrimPrefix(r.URL.Path, "/years/"))
+				mu.Unlock()
+				http.ServeFile(w, r, "../testdata/incomplete.json")
+			}
+		}))
+	defer ts.Close()
+	pick := func(t *testing.T, args ...string) string {
+		t.Helper()
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs(append([]string{"random-show"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		show, err := c.getConstrainedRandomShow(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return show.Date
+	}
+	got := pick(t, "--year", "1994", "--seed", "7")
+	if got != "1994-04-06" {
+		t.Errorf("got %s want 1994-04-06", got)
+	}
+	for i := 0; i < 3; i++ {
+		if again := pick(t, "--year", "1994", "--seed", "7"); again != got {
+			t.Errorf("same seed picked %s, then %s", got, again)
+		}
+	}
+	mu.Lock()
+	years = nil
+	mu.Unlock()
+	pick(t, "--era", "1.0", "--seed", "7")
+	if len(years) < 2 {
+		t.Errorf("wanted a request for each year in the era, got %v", years)
+	}
+	t.Run("year and era together error", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"random-show", "--year", "1994", "--era", "1.0"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+}
+
+func TestHeadOnly(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	full, err := c.getShows(context.Background(), c.FormatURL("shows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full.Shows[0].Tracks) == 0 {
+		t.Fatal("fixture should include tracks")
+	}
+	c.HeadOnly = true
+	got, err := c.getShows(context.Background(), c.FormatURL("shows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Shows[0].Tracks) != 0 {
+		t.Errorf("got %d tracks want none", len(got.Shows[0].Tracks))
+	}
+	// everything but the tracks should match
+	full.Shows[0].Tracks = got.Shows[0].Tracks
+	if !reflect.DeepEqual(got, full) {
+		t.Errorf("got %+v want %+v", got, full)
+	}
+}
+
+func TestSegues(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/segue_show.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "--segues"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "show.segues.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+}
+
+func TestMinTracks(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows_track_counts.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name      string
+		minTracks string
+		dates     []string
+	}{
+		{"none", "0", []string{"1988-10-21", "1988-10-22", "1988-10-23"}},
+		{"drops fragments", "3", []string{"1988-10-21", "1988-10-23"}},
+		{"keeps shows without tracks", "6", []string{"1988-10-23"}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs([]string{"shows", "--min-tracks", tc.minTracks}); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			shows, err := c.getShows(context.Background(), c.FormatURL(showsPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var dates []string
+			for _, show := range shows.Shows {
+				dates = append(dates, show.Date)
+			}
+			if !reflect.DeepEqual(dates, tc.dates) {
+				t.Errorf("got shows %v want %v", dates, tc.dates)
+			}
+		})
+	}
+	t.Run("shows list only", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"tracks", "--min-tracks", "5"}); err == nil {
+			t.Error("wanted an error for tracks")
+		}
+	})
+}
+
+func TestSetlistNotation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fixture string
+		golden  string
+	}{
+		{fixture: "show.json", golden: "show.notation.golden"},
+		{fixture: "segue_show.json", golden: "show.segues.notation.golden"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.fixture, func(t *testing.T) {
+			t.Parallel()
+			ts := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, "../testdata/"+tc.fixture)
+				}))
+			defer ts.Close()
+			buf := &bytes.Buffer{}
+			c := NewClient("dummy", buf)
+			if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "--setlist-notation"}); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			if err := c.run(context.Background(), "shows"); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			want := getGoldenValue(t, tc.golden, got, *updateGolden)
+			if got != want {
+				t.Errorf("got\n%s want\n%s", got, want)
+			}
+		})
+	}
+	t.Run("needs a single show", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "--setlist-notation"}); err == nil {
+			t.Error("wanted an error for a shows list")
+		}
+	})
+}
+
+func TestAuthCheck(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.Header.Get("Authorization") != "Bearer good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	check := func(t *testing.T, key string) (string, error) {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		c := NewClient(key, buf)
+		if err := c.fromArgs([]string{"auth-check", "--retries", "2"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		err := c.run(context.Background(), "auth-check")
+		return buf.String(), err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := check(t, "good-key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "api key is valid\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		_, err := check(t, "bad-key")
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("got %v want ErrUnauthorized", err)
+		}
+		if !strings.Contains(err.Error(), "check your api key") {
+			t.Errorf("wanted a message about the key, got %v", err)
+		}
+		// a rejected key is final, so it isn't retried
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Errorf("got %d requests want 1", got)
+		}
+	})
+}
+
+func TestTSV(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows_two_tours.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-o", "tsv"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "shows.tsv.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+
+	t.Run("lists only", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-o", "tsv"}); err == nil {
+			t.Error("wanted an error for show details")
+		}
+	})
+	t.Run("summaries of lists are rejected up front", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"shows", "--group-by", "venue"},
+			{"shows", "--count"},
+			{"songs", "--by-artist"},
+			{"songs", "--breakdown"},
+			{"venues", "--by-state"},
+		} {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(append(args, "-o", "tsv")); err == nil {
+				t.Errorf("%v: wanted an error", args)
+			}
+		}
+	})
+}
+
+func TestTracksTSVDurations(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/tracks.json")
+		}))
+	defer ts.Close()
+	for format, pattern := range map[string]string{
+		"seconds": `^\d+$`,
+		"clock":   `^\d+:\d{2}:\d{2}$`,
+	} {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs([]string{"tracks", "-o", "tsv", "--duration-format", format}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "tracks"); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) < 2 {
+			t.Fatalf("%s: got %q", format, buf.String())
+		}
+		for _, line := range lines[1:] {
+			duration := strings.Split(line, "\t")[5]
+			if !regexp.MustCompile(pattern).MatchString(duration) {
+				t.Errorf("%s: got duration %q", format, duration)
+			}
+		}
+	}
+}
+
+func TestTracksWithShowContext(t *testing.T) {
+	t.Parallel()
+	var showRequests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/tracks":
+				http.ServeFile(w, r, "../testdata/tracks.json")
+			case "/shows/217", "/shows/323":
+				atomic.AddInt32(&showRequests, 1)
+				http.ServeFile(w, r, "../testdata/show_"+strings.TrimPrefix(r.URL.Path, "/shows/")+".json")
+			default:
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"tracks", "--with-show-context"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	tracks, err := c.getTracks(context.Background(), c.FormatURL(tracksPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]TrackShowContext{
+		4270: {SetPosition: 2, ShowTracks: 7},
+		6693: {SetPosition: 4, ShowTracks: 5},
+	}
+	for _, track := range tracks.Tracks {
+		if track.ShowContext == nil {
+			t.Errorf("no show context for track %d", track.ID)
+			continue
+		}
+		if *track.ShowContext != want[track.ID] {
+			t.Errorf("track %d: got %+v want %+v", track.ID, *track.ShowContext, want[track.ID])
+		}
+	}
+	if got := atomic.LoadInt32(&showRequests); got != 2 {
+		t.Errorf("got %d show requests want 2", got)
+	}
+	if err := tracks.PrettyPrint(buf, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "Set 2 #2") || !strings.Contains(got, "Show Tracks:") {
+		t.Errorf("wanted the show context columns, got\n%s", got)
+	}
+}
+
+func TestJSONIDStrings(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	print := func(t *testing.T, args ...string) string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(append([]string{"shows", "-s", "1990-04-05", "-o", "json"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	plain := print(t)
+	quoted := print(t, "--json-numbers-as-strings")
+	for _, want := range []string{`"id": "696"`, `"tour_id": "8"`, `"id": "14073"`} {
+		if !strings.Contains(quoted, want) {
+			t.Errorf("wanted %s in\n%s", want, quoted)
+		}
+	}
+	// apart from the quotes, the output (field order included) is the same
+	unquoted := regexp.MustCompile(`("(?:id|\w+_id)": )"(\d+)"`).ReplaceAllString(quoted, "$1$2")
+	if unquoted != plain {
+		t.Errorf("got\n%s want\n%s", unquoted, plain)
+	}
+}
+
+func TestAdjacentShow(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/years":
+				http.ServeFile(w, r, "../testdata/years_adjacent.json")
+			case "/years/1993", "/years/1994", "/years/1995":
+				http.ServeFile(w, r, "../testdata/year_"+strings.TrimPrefix(r.URL.Path, "/years/")+".json")
+			default:
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		date string
+		flag string
+		want string
+	}{
+		{"1994-10-29", "--next", "1994-10-31"},
+		{"1994-10-31", "--next", "1995-12-31"},
+		{"1994-11-02", "--prev", "1994-10-31"},
+		{"1994-10-29", "--prev", "1993-12-31"},
+		{"2003-02-28", "--prev", "1995-12-31"},
+		{"1992-05-01", "--next", "1993-12-30"},
+	}
+	for _, tc := range testCases {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "-s", tc.date, tc.flag}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		got, err := c.adjacentShowDate(context.Background(), c.Query, c.Adjacent)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.date, tc.flag, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s %s: got %s want %s", tc.date, tc.flag, got, tc.want)
+		}
+	}
+
+	t.Run("past the last show", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if _, err := c.adjacentShowDate(context.Background(), "1995-12-31", 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v want ErrNotFound", err)
+		}
+	})
+	t.Run("needs a date", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "-s", "696", "--next"}); err == nil {
+			t.Error("wanted an error for a show id")
+		}
+	})
+}
+
+func TestEraCounts(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name  string
+		years string
+		want  []int
+	}{
+		{"span as one year", "../testdata/years_counts.json", []int{280, 41, 51, 54}},
+		{"span split into years", "../testdata/years_counts_normalized.json", []int{280, 41, 51, 54}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ts := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/years" {
+						http.ServeFile(w, r, tc.years)
+						return
+					}
+					http.ServeFile(w, r, "../testdata/eras.json")
+				}))
+			defer ts.Close()
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs([]string{"eras", "--counts"}); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			results, err := c.Result(context.Background(), erasPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, era := range results.(EraCountsOutput) {
+				got = append(got, era.ShowCount)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVenueSongs(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/venues/the-academy":
+				http.ServeFile(w, r, "../testdata/venue_two_shows.json")
+			case "/shows/472":
+				http.ServeFile(w, r, "../testdata/venue_show_472.json")
+			case "/shows/473":
+				http.ServeFile(w, r, "../testdata/venue_show_473.json")
+			default:
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name  string
+		args  []string
+		shows int
+		songs []VenueSong
+	}{
+		{
+			name:  "every show",
+			args:  []string{"venues", "-s", "the-academy", "--songs"},
+			shows: 2,
+			songs: []VenueSong{
+				{Title: "Foam", TimesPlayed: 2},
+				{Title: "Harry Hood", TimesPlayed: 2},
+				{Title: "Runaway Jim", TimesPlayed: 2},
+				{Title: "Llama", TimesPlayed: 1},
+			},
+		},
+		{
+			name:  "limited",
+			args:  []string{"venues", "-s", "the-academy", "--songs", "--limit", "1"},
+			shows: 1,
+			songs: []VenueSong{
+				{Title: "Runaway Jim", TimesPlayed: 2},
+				{Title: "Foam", TimesPlayed: 1},
+				{Title: "Harry Hood", TimesPlayed: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			results, err := c.Result(context.Background(), venuesPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := VenueSongsOutput{Venue: "The Academy", Shows: tc.shows, Songs: tc.songs}
+			if !reflect.DeepEqual(results, want) {
+				t.Errorf("got %+v want %+v", results, want)
+			}
+		})
+	}
+	t.Run("needs venue details", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"venues", "--songs"}); err == nil {
+			t.Error("wanted an error for the venues list")
+		}
+	})
+}
+
+func TestTourDiff(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/tours/fall-tour-1997":
+				http.ServeFile(w, r, "../testdata/tour_fall_1997.json")
+			case "/tours/fall-tour-1998":
+				http.ServeFile(w, r, "../testdata/tour_fall_1998.json")
+			default:
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"tour-diff", "fall-tour-1997", "--against", "fall-tour-1998"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	results, err := c.Result(context.Background(), tourDiffPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TourDiffOutput{
+		Tour:          "Fall Tour 1997",
+		Against:       "Fall Tour 1998",
+		OnlyInTour:    []string{"Emotional Rescue", "Mike's Song", "Tweezer Reprise"},
+		OnlyInAgainst: []string{"Led Zeppelin Loving Cup", "Loving Cup"},
+		Shared:        3,
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("got %+v want %+v", results, want)
+	}
+
+	t.Run("needs a tour to compare against", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"tour-diff", "fall-tour-1997"}); err == nil {
+			t.Error("wanted an error without --against")
+		}
+	})
+}
+
+func TestTourExpand(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/shows/3" {
+				http.ServeFile(w, r, "../testdata/tour_show.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/tour.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"tours", "-s", "1985-tour", "-v", "--expand"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "tours"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "tour.expanded.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+
+	t.Run("expanded shows keep the print options", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs([]string{"tours", "-s", "1985-tour", "-v", "--expand", "--duration-format", "seconds"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "tours"); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		if !regexp.MustCompile(`Anarchy\s+123\b`).MatchString(got) {
+			t.Errorf("wanted the setlist in seconds, got\n%s", got)
+		}
+	})
+}
+
+func TestIncludeEmpty(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/venue_empty.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"venues", "-s", "the-new-room", "--include-empty"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "venues"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "venue.empty.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+}
+
+func TestGroupShowsByVenue(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows_two_tours.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "--group-by", "venue"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "shows.venue.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"shows", "--group-by", "tour"},
+		{"shows", "-s", "1997-12-31", "--group-by", "venue"},
+		{"venues", "--group-by", "venue"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-pp", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	results, err := c.Result(context.Background(), "shows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shows, ok := results.(ShowsOutput)
+	if !ok {
+		t.Fatalf("got %T want ShowsOutput", results)
+	}
+	if len(shows.Shows) != 1 || shows.Shows[0].Date != "1990-04-05" {
+		t.Errorf("got shows %+v", shows.Shows)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("didn't want anything printed, got\n%s", buf.String())
+	}
+
+	c.Count = true
+	results, err = c.Result(context.Background(), "shows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := results.(CountOutput); !ok {
+		t.Errorf("got %T want CountOutput", results)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// the same show twice, then a new one, then stop watching
+			switch atomic.AddInt32(&requests, 1) {
+			case 1, 2:
+				http.ServeFile(w, r, "../testdata/shows.json")
+			case 3:
+				http.ServeFile(w, r, "../testdata/shows_two_tours.json")
+			default:
+				cancel()
+				http.ServeFile(w, r, "../testdata/shows_two_tours.json")
+			}
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "--last", "--watch", "5m"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	// each wait is over at once
+	c.After = func(d time.Duration) <-chan time.Time {
+		if d != 5*time.Minute {
+			t.Errorf("got a wait of %s want 5m", d)
+		}
+		ch := make(chan time.Time, 1)
+		ch <- c.Now()
+		return ch
+	}
+	if err := c.run(ctx, "shows"); err != nil {
+		t.Fatal(err)
+	}
+	outputs := strings.Split(buf.String(), batchSeparator+"\n")
+	if len(outputs) != 2 {
+		t.Fatalf("wanted the first show and one new one, got\n%s", buf.String())
+	}
+	if !strings.Contains(outputs[0], "1990-04-05") || !strings.Contains(outputs[1], "1997-12-31") {
+		t.Errorf("got\n%s", buf.String())
+	}
+
+	for _, args := range [][]string{
+		{"shows", "--watch", "5m"},
+		{"venues", "--last", "--watch", "5m"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+
+	batch := filepath.Join(t.TempDir(), "commands.txt")
+	if err := os.WriteFile(batch, []byte("shows --last --watch 5m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewClient("dummy", io.Discard).runBatch(context.Background(), batch); err == nil {
+		t.Error("wanted an error for watch in a batch")
+	}
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-t", "sbd", "--count"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1759\n" {
+		t.Errorf("got %q want %q", buf.String(), "1759\n")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("got %d requests want 1", n)
+	}
+	t.Run("details can't be counted", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "-s", "1994-04-04", "--count"}); err == nil {
+			t.Error("wanted error, got nil")
+		}
+	})
+	t.Run("client-side filters can't be counted", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"shows", "--since", "7d"},
+			{"shows", "--sbd", "-t", "jamcharts"},
+			{"tracks", "--exclude-tag", "audience"},
+			{"shows", "--min-likes", "5"},
+			{"shows", "--min-tracks", "10"},
+		} {
+			if err := NewClient("dummy", io.Discard).fromArgs(append(args, "--count")); err == nil {
+				t.Errorf("%v: wanted an error with --count", args)
+			}
+		}
+		// sbd alone is sent as the tag, so the api's total counts it
+		if err := NewClient("dummy", io.Discard).fromArgs([]string{"shows", "--sbd", "--count"}); err != nil {
+			t.Errorf("got %v for sbd with count", err)
+		}
+	})
+}
+
+func TestSBD(t *testing.T) {
+	t.Parallel()
+	t.Run("sent as a tag for shows", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"shows", "--sbd"}); err != nil {
+			t.Fatal(err)
+		}
+		url := c.FormatURL("shows")
+		if !strings.Contains(url, "tag=sbd") {
+			t.Errorf("wanted tag=sbd in %s", url)
+		}
+	})
+	t.Run("filtered client-side for years", func(t *testing.T) {
+		// mark the second show as an audience recording
+		b, err := os.ReadFile("../testdata/incomplete.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp map[string]any
+		if err := json.Unmarshal(b, &resp); err != nil {
+			t.Fatal(err)
+		}
+		resp["data"].([]any)[1].(map[string]any)["sbd"] = false
+		ts := httptest.NewTLSServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.RawQuery, "tag=") {
+					t.Errorf("years shouldn't get a tag param, got %s", r.URL.RawQuery)
+				}
+				_ = json.NewEncoder(w).Encode(resp)
+			}))
+		defer ts.Close()
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"years", "-s", "1994", "--sbd"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		got, err := c.getYear(context.Background(), c.FormatURL("years"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dates []string
+		for _, s := range got.Shows {
+			dates = append(dates, s.Date)
+		}
+		want := []string{"1994-04-04", "1994-04-06"}
+		if !reflect.DeepEqual(dates, want) {
+			t.Errorf("got %v want %v", dates, want)
+		}
+	})
+}
+
+func TestPrefetchVenue(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/shows/1990-04-05": "../testdata/show.json",
+		"/venues/339":       "../testdata/mccabes.json",
+	}
+	var venueRequests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/shows/1983-12-02" {
+				fmt.Fprint(w, `{"data": {"id": 1, "date": "1983-12-02", "venue_name": "Harris-Millis Cafeteria", "venue": {"name": "Harris-Millis Cafeteria"}, "tracks": []}}`)
+				return
+			}
+			file, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if strings.HasPrefix(r.URL.Path, "/venues/") {
+				atomic.AddInt32(&venueRequests, 1)
+			}
+			http.ServeFile(w, r, file)
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	url := ts.URL + "/shows/1990-04-05"
+
+	show, err := c.getShow(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if show.Venue.ShowDates != nil || atomic.LoadInt32(&venueRequests) != 0 {
+		t.Errorf("venue fetched without --prefetch-venue: %+v", show.Venue)
+	}
+
+	c.PrefetchVenue = true
+	show, err = c.getShow(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VenueOutput{
+		Name:       "J.J. McCabe's",
+		Location:   "Boulder, CO",
+		ShowsCount: 1,
+		ShowDates:  []string{"1990-04-05"},
+		OtherNames: []string{"McCabe's"},
+		Latitude:   40.014986,
+		Longitude:  -105.270546,
+		City:       "Boulder",
+		State:      "CO",
+		Country:    "USA",
+	}
+	if !reflect.DeepEqual(show.Venue, want) {
+		t.Errorf("got %+v want %+v", show.Venue, want)
+	}
+	if n := atomic.LoadInt32(&venueRequests); n != 1 {
+		t.Errorf("got %d venue requests, want 1", n)
+	}
+
+	t.Run("a show without a venue id keeps its venue", func(t *testing.T) {
+		show, err := c.getShow(context.Background(), ts.URL+"/shows/1983-12-02")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if show.Venue.Name != "Harris-Millis Cafeteria" {
+			t.Errorf("got venue %+v, wanted the one in the show", show.Venue)
+		}
+		if n := atomic.LoadInt32(&venueRequests); n != 1 {
+			t.Errorf("got %d venue requests, wanted none for venue 0", n-1)
+		}
+	})
+}
+
+func TestGetVenuesByID(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			switch r.URL.Path {
+			case "/venues/339":
+				http.ServeFile(w, r, "../testdata/mccabes.json")
+			case "/venues/11":
+				http.ServeFile(w, r, "../testdata/venue.json")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	venues, err := c.getVenuesByID(context.Background(), []int{339, 11, 339})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if venues[339].Name != "J.J. McCabe's" || venues[11].Name != "The Academy" {
+		t.Errorf("venues not mapped to their ids: %+v", venues)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("got %d requests for 2 distinct venues", n)
+	}
+	if _, err := c.getVenuesByID(context.Background(), []int{1}); err == nil {
+		t.Error("wanted an error for a missing venue")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	get := func(t *testing.T, c *Client) {
+		t.Helper()
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		var resp ErasResponse
+		if err := c.Get(context.Background(), c.FormatURL(erasPath), &resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Run("debug records requests", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", io.Discard)
+		c.Logger = slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		get(t, c)
+		for _, want := range []string{"level=DEBUG", "method=GET", "url=" + ts.URL + "/eras", "status=200"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("wanted %q in %q", want, buf.String())
+			}
+		}
+		if strings.Contains(buf.String(), "dummy") {
+			t.Errorf("api key logged: %q", buf.String())
+		}
+	})
+	t.Run("default level skips debug", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", io.Discard)
+		c.ErrOutput = buf
+		get(t, c)
+		if buf.Len() != 0 {
+			t.Errorf("wanted no records, got %q", buf.String())
+		}
+	})
+	t.Run("log level flag", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", io.Discard)
+		c.ErrOutput = buf
+		if err := c.fromArgs([]string{"eras", "--log-level", "debug"}); err != nil {
+			t.Fatal(err)
+		}
+		get(t, c)
+		if !strings.Contains(buf.String(), "level=DEBUG msg=request") {
+			t.Errorf("wanted a debug record, got %q", buf.String())
+		}
+	})
+	t.Run("invalid log level", func(t *testing.T) {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs([]string{"eras", "--log-level", "loud"}); err == nil {
+			t.Error("wanted an error for an unknown level")
+		}
+	})
+}
+
+func TestYearRange(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/years.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name  string
+		args  []string
+		want  []string
+		count int
+	}{
+		{"bucket overlaps", []string{"--from", "1985", "--to", "1988"}, []string{"1983-1987", "1988"}, 78},
+		{"from only", []string{"--from", "1989"}, []string{"1989"}, 64},
+		{"to only", []string{"--to", "1987"}, []string{"1983-1987"}, 34},
+		{"no range", nil, []string{"1983-1987", "1988", "1989"}, 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(append([]string{"years"}, tc.args...)); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			got, err := c.getYears(context.Background(), c.FormatURL(yearsPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var dates []string
+			for _, y := range got.Years {
+				dates = append(dates, y.Date)
+			}
+			if !reflect.DeepEqual(dates, tc.want) {
+				t.Errorf("got %v want %v", dates, tc.want)
+			}
+			if got.ShowCount != tc.count {
+				t.Errorf("got %d shows want %d", got.ShowCount, tc.count)
+			}
+		})
+	}
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"years", "--from", "2000", "--to", "1994"}); err == nil {
+		t.Error("wanted an error for --from after --to")
+	}
+}
+
+func TestNormalizeDates(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/years":           "../testdata/years.json",
+		"/years/1983-1987": "../testdata/year_1983_1987.json",
+		"/eras":            "../testdata/eras.json",
+		"/eras/1.0":        "../testdata/era.json",
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, files[r.URL.Path])
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.NormalizeDates = true
+
+	years, err := c.getYears(context.Background(), c.FormatURL(yearsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Year{
+		{Date: "1983", ShowCount: 1},
+		{Date: "1984", ShowCount: 1},
+		{Date: "1985", ShowCount: 2},
+		{Date: "1986", ShowCount: 0},
+		{Date: "1987", ShowCount: 0},
+		{Date: "1988", ShowCount: 44},
+		{Date: "1989", ShowCount: 64},
+	}
+	if !reflect.DeepEqual(years.Years, want) {
+		t.Errorf("got %v want %v", years.Years, want)
+	}
+
+	eras, err := c.getEras(context.Background(), c.FormatURL(erasPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOne := []string{"1983", "1984", "1985", "1986", "1987", "1988"}
+	if !reflect.DeepEqual(eras["1.0"][:6], wantOne) {
+		t.Errorf("got %v want %v", eras["1.0"][:6], wantOne)
+	}
+	if len(eras["4.0"]) != 3 {
+		t.Errorf("got %v want 3 years in era 4.0", eras["4.0"])
+	}
+
+	// an era without a span is left as is
+	c.Query = "1.0"
+	era, err := c.getEra(context.Background(), c.entityURL(erasPath, "1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(era.Years) != 12 {
+		t.Errorf("got %v want 12 years", era.Years)
+	}
+
+	// spans are only expanded with --normalize-dates
+	c.NormalizeDates = false
+	c.Query = ""
+	years, err = c.getYears(context.Background(), c.FormatURL(yearsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if years.Years[0].Date != "1983-1987" {
+		t.Errorf("got %q want 1983-1987", years.Years[0].Date)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+	var attempts int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			switch r.URL.Path {
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "/flaky":
+				if atomic.LoadInt32(&attempts) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				http.ServeFile(w, r, "../testdata/years.json")
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+	defer ts.Close()
+	newClient := func() *Client {
+		c := NewClient("dummy", io.Discard)
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		c.retryWait = 0
+		c.Retries = 3
+		c.RetryBudget = 4
+		return c
+	}
+
+	t.Run("budget caps attempts", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		c := newClient()
+		for _, p := range []string{"/a", "/b", "/c"} {
+			var resp YearsResponse
+			if err := c.Get(context.Background(), ts.URL+p, &resp); err == nil {
+				t.Fatalf("wanted an error for %s", p)
+			}
+		}
+		// /a is retried 3 times, /b once before the budget of 4 runs out, and
+		// /c not at all
+		if got := atomic.LoadInt32(&attempts); got != 7 {
+			t.Errorf("got %d attempts want 7", got)
+		}
+	})
+	t.Run("not found isn't retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		c := newClient()
+		var resp YearsResponse
+		if err := c.Get(context.Background(), ts.URL+"/missing", &resp); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got %v want ErrNotFound", err)
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("got %d attempts want 1", got)
+		}
+	})
+	t.Run("recovers after a retry", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		c := newClient()
+		var resp YearsResponse
+		if err := c.Get(context.Background(), ts.URL+"/flaky", &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Data) != 3 || atomic.LoadInt32(&attempts) != 2 {
+			t.Errorf("got %d years in %d attempts, want 3 in 2", len(resp.Data), atomic.LoadInt32(&attempts))
+		}
+	})
+	t.Run("raw output is retried too", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		buf := &bytes.Buffer{}
+		c := newClient()
+		c.Output = buf
+		if err := c.getAndPrintRaw(context.Background(), ts.URL+"/flaky"); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() == 0 || atomic.LoadInt32(&attempts) != 2 {
+			t.Errorf("got %q in %d attempts, want the years in 2", buf.String(), atomic.LoadInt32(&attempts))
+		}
+	})
+
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "--retries", "-1"}); err == nil {
+		t.Error("wanted an error for negative retries")
+	}
+}
+
+func TestMetricsFile(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/years":
+				http.ServeFile(w, r, "../testdata/years.json")
+			case "/reba.mp3":
+				fmt.Fprint(w, "0123456789")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	dir := t.TempDir()
+	c.MetricsFile = filepath.Join(dir, "metrics.prom")
+
+	var resp YearsResponse
+	if err := c.Get(context.Background(), ts.URL+"/years", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), ts.URL+"/missing", &resp); err == nil {
+		t.Fatal("wanted an error for /missing")
+	}
+	if _, err := c.DownloadTrack(context.Background(), ts.URL+"/reba.mp3", "reba.mp3", dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.writeMetricsFile(1500 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(c.MetricsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"# TYPE phishin_requests_total counter\nphishin_requests_total 3\n",
+		"# TYPE phishin_request_errors_total counter\nphishin_request_errors_total 1\n",
+		"# TYPE phishin_downloaded_bytes_total counter\nphishin_downloaded_bytes_total 10\n",
+		"# TYPE phishin_run_duration_seconds gauge\nphishin_run_duration_seconds 1.500\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+
+	t.Run("not in a batch", func(t *testing.T) {
+		dir := t.TempDir()
+		batch := filepath.Join(dir, "commands.txt")
+		if err := os.WriteFile(batch, []byte("eras --metrics-file "+filepath.Join(dir, "metrics.prom")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := NewClient("dummy", io.Discard).runBatch(context.Background(), batch)
+		if err == nil || !strings.Contains(err.Error(), "--metrics-file") {
+			t.Errorf("got %v, wanted an error for --metrics-file in a batch", err)
+		}
+	})
+}
+
+func TestExcludeTag(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/tracks.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name string
+		args []string
+		want []int
+	}{
+		{"none", nil, []int{4270, 6693}},
+		{"one tag", []string{"--exclude-tag", "jamcharts"}, []int{4270}},
+		{"comma-separated", []string{"--exclude-tag", "audience,sbd"}, []int{4270}},
+		{"repeated", []string{"--exclude-tag", "audience", "--exclude-tag", "tease"}, []int{4270, 6693}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(append([]string{"tracks"}, tc.args...)); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			got, err := c.getTracks(context.Background(), c.FormatURL(tracksPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, track := range got.Tracks {
+				ids = append(ids, track.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.want) {
+				t.Errorf("got %v want %v", ids, tc.want)
+			}
+		})
+	}
+}
+
+func TestMinLikes(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/shows" {
+				http.ServeFile(w, r, "../testdata/shows.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/tracks.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name     string
+		minLikes string
+		tracks   []int
+		shows    int
+	}{
+		{"none", "0", []int{4270, 6693}, 1},
+		{"some", "2", []int{4270}, 1},
+		{"all", "6", nil, 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs([]string{"tracks", "--min-likes", tc.minLikes}); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL
+			c.HTTPClient = ts.Client()
+			tracks, err := c.getTracks(context.Background(), c.FormatURL(tracksPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, track := range tracks.Tracks {
+				ids = append(ids, track.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.tracks) {
+				t.Errorf("got tracks %v want %v", ids, tc.tracks)
+			}
+			shows, err := c.getShows(context.Background(), c.FormatURL(showsPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shows.Shows) != tc.shows {
+				t.Errorf("got %d shows want %d", len(shows.Shows), tc.shows)
+			}
+		})
+	}
+}
+
+func TestTracksSong(t *testing.T) {
+	t.Parallel()
+	var gotQuery string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.RawQuery
+			http.ServeFile(w, r, "../testdata/tracks.json")
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"tracks", "--song", "harry-hood", "-pp", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	want := ts.URL + "/tracks?song_slug=harry-hood&per_page=5"
+	if got := c.FormatURL(tracksPath); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+	if err := c.run(context.Background(), "tracks"); err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "song_slug=harry-hood&per_page=5" {
+		t.Errorf("got query %q", gotQuery)
+	}
+
+	for _, args := range [][]string{
+		{"shows", "--song", "harry-hood"},
+		{"tracks", "-s", "6693", "--song", "harry-hood"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestParam(t *testing.T) {
+	t.Parallel()
+	var gotQuery string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.RawQuery
+			http.ServeFile(w, r, "../testdata/shows.json")
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "-pp", "5", "--param", "venue_id=5", "--param", "note=a b"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "per_page=5&venue_id=5&note=a+b"; gotQuery != want {
+		t.Errorf("got query %q want %q", gotQuery, want)
+	}
+
+	for _, p := range []string{"venue_id", "=5", ""} {
+		if err := NewClient("dummy", io.Discard).fromArgs([]string{"shows", "--param", p}); err == nil {
+			t.Errorf("%q: wanted an error", p)
+		}
+	}
+}
+
+func TestSortTracks(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "--sort-tracks", "duration:desc"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	show, err := c.getShow(context.Background(), c.FormatURL(showsPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, tr := range show.Tracks[:3] {
+		titles = append(titles, tr.Title)
+	}
+	if want := []string{"You Enjoy Myself", "Reba", "David Bowie"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("got %v want %v", titles, want)
+	}
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); strings.Index(got, "You Enjoy Myself") > strings.Index(got, "Reba") {
+		t.Errorf("wanted the longest track listed first in\n%s", got)
+	}
+
+	for _, v := range []string{"length", "duration:up", "title:"} {
+		if err := NewClient("dummy", io.Discard).fromArgs([]string{"shows", "-s", "1990-04-05", "--sort-tracks", v}); err == nil {
+			t.Errorf("%q: wanted an error", v)
+		}
+	}
+}
+
+func TestNoAPIKey(t *testing.T) {
+	t.Parallel()
+	var sentAuth int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Header["Authorization"]; ok {
+				atomic.AddInt32(&sentAuth, 1)
+			}
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	c := NewClient("from-env", io.Discard)
+	if err := c.fromArgs([]string{"eras", "--no-api-key"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.APIKey != "" {
+		t.Errorf("got api key %q, wanted none", c.APIKey)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	c.RawOutput = true
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&sentAuth); n != 0 {
+		t.Errorf("got %d requests with an authorization header, wanted none", n)
+	}
+
+	if err := NewClient("", io.Discard).fromArgs([]string{"eras", "--no-api-key", "--api-key", "abc"}); err == nil {
+		t.Error("wanted an error for no-api-key with an api key")
+	}
+}
+
+func TestLike(t *testing.T) {
+	t.Parallel()
+	var gotMethod, gotAuth, gotBody string
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			b, _ := io.ReadAll(r.Body)
+			gotMethod, gotAuth, gotBody = r.Method+" "+r.URL.Path, r.Header.Get("Authorization"), string(b)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data": {"likes_count": 12}}`)
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("good-key", buf)
+	if err := c.fromArgs([]string{"like", "shows", "696"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "like"); err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != "POST /likes" {
+		t.Errorf("got %s want POST /likes", gotMethod)
+	}
+	if gotAuth != "Bearer good-key" {
+		t.Errorf("got authorization %q", gotAuth)
+	}
+	if want := `{"likable_type":"Show","likable_id":696}`; gotBody != want {
+		t.Errorf("got body %s want %s", gotBody, want)
+	}
+	if got, want := buf.String(), "liked show 696 (12 likes)\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	c.APIKey = "bad-key"
+	if err := c.run(context.Background(), "like"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("got %v want ErrUnauthorized", err)
+	}
+	for _, args := range [][]string{
+		{"like"},
+		{"like", "songs", "1"},
+		{"like", "shows", "1997-11-22"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestPostRetries(t *testing.T) {
+	t.Parallel()
+	var attempts int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data": {"likes_count": 12}}`)
+		}))
+	defer ts.Close()
+	newClient := func() *Client {
+		c := NewClient("dummy", io.Discard)
+		c.HTTPClient = ts.Client()
+		c.retryWait = 0
+		c.Retries = 3
+		return c
+	}
+	body := map[string]any{"likable_type": "Show", "likable_id": 696}
+
+	t.Run("post isn't retried by default", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		if err := newClient().Post(context.Background(), ts.URL+"/likes", body, nil); err == nil {
+			t.Fatal("wanted an error")
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("got %d attempts want 1", got)
+		}
+	})
+	t.Run("retry-non-idempotent opts in", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		c := newClient()
+		if err := c.fromArgs([]string{"like", "shows", "696", "--retry-non-idempotent"}); err != nil {
+			t.Fatal(err)
+		}
+		c.Retries = 3
+		if err := c.Post(context.Background(), ts.URL+"/likes", body, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := atomic.LoadInt32(&attempts); got != 2 {
+			t.Errorf("got %d attempts want 2", got)
+		}
+	})
+}
+
+func TestShare(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			switch r.URL.Path {
+			case "/tracks/6693":
+				http.ServeFile(w, r, "../testdata/track.json")
+			case "/shows/1":
+				http.ServeFile(w, r, "../testdata/show.json")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer ts.Close()
+	tests := []struct {
+		args     []string
+		want     string
+		requests int32
+	}{
+		{[]string{"shows", "-s", "1997-11-22", "--share"}, "https://phish.in/1997-11-22\n", 0},
+		{[]string{"songs", "-s", "tweezer", "--share"}, "https://phish.in/songs/tweezer\n", 0},
+		{[]string{"shows", "-s", "1", "--share"}, "https://phish.in/1990-04-05\n", 1},
+		{[]string{"tracks", "-s", "6693", "--share"}, "https://phish.in/1993-04-09/stash\n", 1},
+	}
+	for _, tc := range tests {
+		atomic.StoreInt32(&requests, 0)
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), tc.args[0]); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%v: got %q want %q", tc.args, got, tc.want)
+		}
+		if got := atomic.LoadInt32(&requests); got != tc.requests {
+			t.Errorf("%v: got %d requests want %d", tc.args, got, tc.requests)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"shows", "--share"},
+		{"eras", "-s", "1.0", "--share"},
+	} {
+		if err := NewClient("dummy", io.Discard).fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestDurationFormat(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/tracks/6693":      "../testdata/track.json",
+		"/shows/1990-04-05": "../testdata/show.json",
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, files[r.URL.Path])
+		}))
+	defer ts.Close()
+	print := func(t *testing.T, args ...string) string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), args[0]); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	got := print(t, "tracks", "-s", "6693", "--duration-format", "seconds")
+	if !regexp.MustCompile(`Stash\s+675\s+Set 1`).MatchString(got) {
+		t.Errorf("wanted 675 seconds for stash, got\n%s", got)
+	}
+	got = print(t, "tracks", "-s", "6693")
+	if !strings.Contains(got, "11m 15s") {
+		t.Errorf("wanted the human duration by default, got\n%s", got)
+	}
+	got = print(t, "shows", "-s", "1990-04-05", "--duration-format", "seconds")
+	for _, want := range []string{`Possum\s+408\n`, `Ya Mar\s+427\n`} {
+		if !regexp.MustCompile(want).MatchString(got) {
+			t.Errorf("wanted %s in\n%s", want, got)
+		}
+	}
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"tracks", "--duration-format", "minutes"}); err == nil {
+		t.Error("wanted an error for an unknown duration format")
+	}
+}
+
+func TestClockDurations(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/eras" {
+				http.ServeFile(w, r, "../testdata/eras.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-v", "--duration-format", "clock"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "show.clock.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+}
+
+func TestASCII(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/montreal.json")
+		}))
+	defer ts.Close()
+	print := func(t *testing.T, args ...string) string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(append([]string{"shows"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	got := print(t, "--ascii")
+	if !strings.Contains(got, "Le Spectrum de Montreal  Montreal, Quebec") {
+		t.Errorf("wanted Montréal transliterated, got\n%s", got)
+	}
+	for _, r := range got {
+		if r >= utf8.RuneSelf {
+			t.Fatalf("got non-ascii %q in\n%s", r, got)
+		}
+	}
+	if got := print(t); !strings.Contains(got, "Montréal, Québec") {
+		t.Errorf("wanted the accents kept by default, got\n%s", got)
+	}
+}
+
+func TestCover(t *testing.T) {
+	t.Parallel()
+	b, err := os.ReadFile("../testdata/show_cover.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/blob/") {
+				fmt.Fprint(w, "cover art")
+				return
+			}
+			_, _ = w.Write(bytes.ReplaceAll(b, []byte("https://phish.in/blob/"), []byte(ts.URL+"/blob/")))
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-v", "--cover"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	c.DownloadDir = t.TempDir()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ErrGroup.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	want := "Cover: " + ts.URL + "/blob/album-covers/1990-04-05.jpg\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("wanted %q in\n%s", want, buf.String())
+	}
+	got, err := os.ReadFile(filepath.Join(c.DownloadDir, "1990-04-05", "cover.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "cover art" {
+		t.Errorf("got cover %q", got)
+	}
+
+	t.Run("placeholder without art", func(t *testing.T) {
+		eras := ErasOutput{
+			"1.0": []string{"1983-1987", "1988"},
+			"2.0": []string{"2002", "2003", "2004"},
+			"3.0": []string{"2009", "2013"},
+			"5.0": []string{"2030"},
+		}
+		for date, want := range map[string]string{
+			"1985-03-04": "none (era 1.0)",
+			"2013-10-31": "none (era 3.0)",
+			"2030-12-31": "none (era 5.0)",
+			// the hiatus is in no era
+			"2006-06-01": "none (era unknown)",
+		} {
+			show := ShowOutput{Date: date, eras: eras}
+			if got := show.cover(); got != want {
+				t.Errorf("%s: got %q want %q", date, got, want)
+			}
+		}
+		c := NewClient("dummy", io.Discard)
+		c.DownloadDir = t.TempDir()
+		if err := c.downloadCover(context.Background(), Show{Date: "2013-10-31"}); err == nil {
+			t.Error("wanted an error downloading missing cover art")
+		}
+	})
+}
+
+func TestMergeSets(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/eras" {
+				http.ServeFile(w, r, "../testdata/eras.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		golden string
+		args   []string
+	}{
+		{"show.merged.golden", []string{"shows", "-s", "1990-04-05", "--merge-sets"}},
+		{"show.merged.verbose.golden", []string{"shows", "-s", "1990-04-05", "--merge-sets", "-v"}},
+	}
+	for _, tc := range testCases {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		want := getGoldenValue(t, tc.golden, got, *updateGolden)
+		if got != want {
+			t.Errorf("%s: got\n%s want\n%s", tc.golden, got, want)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/api/v1/shows/1990-04-05": "../testdata/show.json",
+		"/api/v1/shows":            "../testdata/shows.json",
+	}
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, files[r.URL.Path])
+		}))
+	defer ts.Close()
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	for _, args := range [][]string{
+		{"shows", "-s", "1990-04-05", "--record", dir},
+		{"shows", "-pp", "1", "--record", dir},
+	} {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs(args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL + "/api/v1"
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, fixture := range map[string]string{
+		"shows-1990-04-05.json": "../testdata/show.json",
+		"shows_per_page=1.json": "../testdata/shows.json",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s doesn't match the response body", name)
+		}
+	}
+
+	t.Run("raw output is recorded for offline replay", func(t *testing.T) {
+		dir := t.TempDir()
+		var outputs []string
+		for _, args := range [][]string{
+			{"shows", "-s", "1990-04-05", "-r", "--record", dir},
+			{"shows", "-s", "1990-04-05", "-r", "--record", dir, "--offline"},
+		} {
+			buf := &bytes.Buffer{}
+			c := NewClient("dummy", buf)
+			if err := c.fromArgs(args); err != nil {
+				t.Fatal(err)
+			}
+			c.BaseURL = ts.URL + "/api/v1"
+			c.HTTPClient = ts.Client()
+			if err := c.run(context.Background(), "shows"); err != nil {
+				t.Fatalf("%v: %v", args, err)
+			}
+			outputs = append(outputs, buf.String())
+		}
+		if outputs[0] == "" || outputs[0] != outputs[1] {
+			t.Errorf("got\n%s offline, want\n%s", outputs[1], outputs[0])
+		}
+	})
+}
+
+func TestStrictJSON(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/drifted" {
+				http.ServeFile(w, r, "../testdata/years_extra_field.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/years.json")
+		}))
+	defer ts.Close()
+	testCases := []struct {
+		name    string
+		path    string
+		strict  bool
+		wantErr error
+	}{
+		{"lenient ignores extra fields", "/drifted", false, nil},
+		{"strict rejects extra fields", "/drifted", true, ErrUnknownField},
+		{"strict accepts modeled fields", "/years", true, nil},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("dummy", io.Discard)
+			c.HTTPClient = ts.Client()
+			c.StrictJSON = tc.strict
+			var resp YearsResponse
+			err := c.Get(context.Background(), ts.URL+tc.path, &resp)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil && !strings.Contains(err.Error(), "venue_count") {
+				t.Errorf("wanted the error to name the field, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStrictJSONFixtures(t *testing.T) {
+	t.Parallel()
+	c := NewClient("dummy", io.Discard)
+	c.StrictJSON = true
+	fixtures := map[string]func() any{
+		"boulder_search.json":     func() any { return &SearchResponse{} },
+		"mixed_search.json":       func() any { return &SearchResponse{} },
+		"eras.json":               func() any { return &ErasResponse{} },
+		"era.json":                func() any { return &EraResponse{} },
+		"years.json":              func() any { return &YearsResponse{} },
+		"year.json":               func() any { return &YearResponse{} },
+		"year_1983_1987.json":     func() any { return &YearResponse{} },
+		"incomplete.json":         func() any { return &YearResponse{} },
+		"shows.json":              func() any { return &ShowsResponse{} },
+		"shows_two_tours.json":    func() any { return &ShowsResponse{} },
+		"show.json":               func() any { return &ShowResponse{} },
+		"segue_show.json":         func() any { return &ShowResponse{} },
+		"show_cover.json":         func() any { return &ShowResponse{} },
+		"show_on_date.json":       func() any { return &ShowOnDateResponse{} },
+		"songs.json":              func() any { return &SongsResponse{} },
+		"covers.json":             func() any { return &SongsResponse{} },
+		"song.json":               func() any { return &SongResponse{} },
+		"song_tweezer.json":       func() any { return &SongResponse{} },
+		"tours.json":              func() any { return &ToursResponse{} },
+		"tour.json":               func() any { return &TourResponse{} },
+		"venues.json":             func() any { return &VenuesResponse{} },
+		"venues_by_state.json":    func() any { return &VenuesResponse{} },
+		"venue.json":              func() any { return &VenueResponse{} },
+		"mccabes.json":            func() any { return &VenueResponse{} },
+		"tracks.json":             func() any { return &TracksResponse{} },
+		"track.json":              func() any { return &TrackResponse{} },
+		"tags.json":               func() any { return &TagsResponse{} },
+		"tag.json":                func() any { return &TagResponse{} },
+		"years_extra_field.json":  func() any { return partial(&YearsResponse{}) },
+		"shows_track_counts.json": func() any { return &ShowHeadsResponse{} },
+	}
+	for name, target := range fixtures {
+		b, err := os.ReadFile(filepath.Join("../testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.decode(bytes.NewReader(b), target()); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	dir := t.TempDir()
+	b, err := os.ReadFile("../testdata/show.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "shows-1990-04-05.json"), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "--offline", "--record", dir}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	show, err := c.getShow(context.Background(), ts.URL+"/shows/1990-04-05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if show.ID != 696 {
+		t.Errorf("got show %d want 696 from the cache", show.ID)
+	}
+	_, err = c.getShow(context.Background(), ts.URL+"/shows/1997-11-22")
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("got %v want a not cached error", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("got %d requests offline, want none", n)
+	}
+	if err := c.fromArgs([]string{"shows", "--offline"}); err == nil {
+		t.Error("wanted an error for offline without a record dir")
+	}
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-d", "--offline", "--record", dir}); err == nil {
+		t.Error("wanted an error for downloading offline")
+	}
+
+	// each query string has its own recording
+	for name, fixture := range map[string]string{
+		"shows_page=2.json":  "../testdata/shows_two_tours.json",
+		"shows_tag=sbd.json": "../testdata/shows.json",
+	} {
+		b, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"shows", "-p", "2"}, "1997-12-31"},
+		{[]string{"shows", "-t", "sbd"}, "1990-04-05"},
+	} {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs(append(tc.args, "--offline", "--record", dir)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		shows, err := c.getShows(context.Background(), c.FormatURL("shows"))
+		if err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		if got := shows.Shows[0].Date; got != tc.want {
+			t.Errorf("%v: got %s want %s", tc.args, got, tc.want)
+		}
+	}
+	c = NewClient("dummy", io.Discard)
+	if err := c.fromArgs([]string{"shows", "-p", "3", "--offline", "--record", dir}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	if _, err := c.getShows(context.Background(), c.FormatURL("shows")); !errors.Is(err, ErrNotCached) {
+		t.Errorf("got %v want a not cached error for an unrecorded page", err)
+	}
+
+	// raw output reads the recording too
+	buf := &bytes.Buffer{}
+	c = NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-r", "--offline", "--record", dir}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "1990-04-05") {
+		t.Errorf("wanted the recorded show in raw output, got %s", buf.String())
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("got %d requests offline, want none", n)
+	}
+}
+
+func TestPagerBypassedWithoutTerminal(t *testing.T) {
+	t.Parallel()
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, w := range []io.Writer{&bytes.Buffer{}, f} {
+		c := NewClient("dummy", w)
+		if err := c.fromArgs([]string{"shows", "--pager"}); err != nil {
+			t.Fatal(err)
+		}
+		closePager, err := c.startPager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Output != w {
+			t.Errorf("output to %T was piped to a pager", w)
+		}
+		if err := closePager(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestVenuesByLocation(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/venues_by_state.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"venues", "--by-state", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "venues"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Data VenuesByLocationOutput `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := VenuesByLocationOutput{
+		By: "state",
+		Locations: []LocationVenues{
+			{Location: "NY, USA", Venues: 2, Shows: 48},
+			{Location: "VT, USA", Venues: 2, Shows: 33},
+			{Location: "QC, Canada", Venues: 1, Shows: 1},
+		},
+	}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("got %+v want %+v", got.Data, want)
+	}
+
+	venues, err := c.getVenues(context.Background(), c.FormatURL(venuesPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCity := groupVenuesByLocation(venues.Venues, "city")
+	if byCity.Locations[0].Location != "New York, NY" || byCity.Locations[2].Location != "Montréal, QC" {
+		t.Errorf("got cities %+v", byCity.Locations)
+	}
+	if err := c.fromArgs([]string{"venues", "--by-state", "--by-city"}); err == nil {
+		t.Error("wanted an error grouping by two locations")
+	}
+}
+
+func TestFirstLast(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"last", []string{"shows", "--last"}, []string{"per_page=1", "sort_dir=desc", "sort_attr=date"}},
+		{"first", []string{"shows", "--first"}, []string{"per_page=1", "sort_dir=asc", "sort_attr=date"}},
+		{"overrides paging", []string{"shows", "--last", "-p", "3", "-pp", "5"}, []string{"per_page=1", "sort_dir=desc", "sort_attr=date"}},
+		{"keeps the tag", []string{"shows", "--first", "-t", "sbd"}, []string{"tag=sbd", "per_page=1", "sort_dir=asc", "sort_attr=date"}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewClient("dummy", io.Discard)
+			if err := c.fromArgs(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.Parameters, tc.want) {
+				t.Errorf("got %v want %v", c.Parameters, tc.want)
+			}
+		})
+	}
+	for _, args := range [][]string{
+		{"shows", "--first", "--last"},
+		{"tracks", "--last"},
+		{"shows", "-s", "1997-11-22", "--first"},
+	} {
+		c := NewClient("dummy", io.Discard)
+		if err := c.fromArgs(args); err == nil {
+			t.Errorf("%v: wanted an error", args)
+		}
+	}
+}
+
+func TestDedupeVenues(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/venues_dedupe.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"venues", "--dedupe", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "venues"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Data []VenueOutput `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, v := range got.Data {
+		names = append(names, v.Name)
+	}
+	// ruoff has no other names linking it to deer creek, so it stays
+	wantNames := []string{"Deer Creek Music Center", "Ruoff Music Center", "Market Square Arena"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("got %v want %v", names, wantNames)
+	}
+	deerCreek := got.Data[0]
+	if !reflect.DeepEqual(deerCreek.OtherNames, []string{"Verizon Wireless Music Center"}) {
+		t.Errorf("got other names %v", deerCreek.OtherNames)
+	}
+	wantDates := []string{"1995-07-23", "1997-08-13", "2003-07-29"}
+	if !reflect.DeepEqual(deerCreek.ShowDates, wantDates) {
+		t.Errorf("got dates %v want %v", deerCreek.ShowDates, wantDates)
+	}
+	// 1997-08-13 is listed under both venues
+	if deerCreek.ShowsCount != 3 {
+		t.Errorf("got %d shows want 3", deerCreek.ShowsCount)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/eras" {
+				http.ServeFile(w, r, "../testdata/eras.json")
+				return
+			}
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "--highlight", "bowie"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "David **Bowie**") {
+		t.Errorf("wanted bowie marked in\n%s", got)
+	}
+	if strings.Count(got, "**Bowie**") != strings.Count(strings.ToLower(got), "bowie") {
+		t.Errorf("wanted every match marked in\n%s", got)
+	}
+
+	t.Run("a match keeps the theme color", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs([]string{"shows", "-s", "1990-04-05", "-v", "--color", "always", "--highlight", "mccabe"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		want := ansiCyan + "J.J. " + ansiReverse + "McCabe" + ansiReverseOff + "'s" + ansiReset
+		if got := buf.String(); !strings.Contains(got, want) {
+			t.Errorf("wanted %q in %q", want, got)
+		}
+	})
+}
+
+func TestJSONRoot(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/eras.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"eras", "-o", "json", "--json-root", "phishin"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]struct {
+		Data ErasOutput `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got keys %v want just phishin", got)
+	}
+	root, ok := got["phishin"]
+	if !ok {
+		t.Fatalf("no phishin key in %s", buf.String())
+	}
+	if len(root.Data["1.0"]) != 14 || root.Data["4.0"][0] != "2021" {
+		t.Errorf("got %+v", root.Data)
+	}
+}
+
+func TestMarkTours(t *testing.T) {
+	t.Parallel()
+	tours := map[string]string{"/tours/60": "Fall Tour 1997", "/tours/61": "New Year's Run 1997"}
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if name, ok := tours[r.URL.Path]; ok {
+				fmt.Fprintf(w, `{"data": {"name": %q}}`, name)
+				return
+			}
+			http.ServeFile(w, r, "../testdata/shows_two_tours.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"shows", "--mark-tours"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "shows"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	want := getGoldenValue(t, "shows.tours.golden", got, *updateGolden)
+	if got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+}
+
+func TestTourNamesSkipShowsWithoutATour(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/tours/60" {
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"data": {"name": "Fall Tour 1997"}}`)
+		}))
+	defer ts.Close()
+	c := NewClient("dummy", io.Discard)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	names, err := c.getTourNamesByID(context.Background(), []int{0, 60, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]string{60: "Fall Tour 1997"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v want %v", names, want)
+	}
+}
+
+func TestShowTourAndVenueIDs(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/show.json")
+		}))
+	defer ts.Close()
+	for _, args := range [][]string{
+		{"shows", "-s", "1990-04-05", "-o", "json"},
+		{"shows", "-s", "1990-04-05", "-o", "json", "--nested-sets"},
+	} {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(args); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "shows"); err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Data struct {
+				TourID  int `json:"tour_id"`
+				VenueID int `json:"venue_id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		// show.json only has the venue id on its embedded venue
+		if got.Data.TourID != 8 || got.Data.VenueID != 339 {
+			t.Errorf("%v: got tour %d venue %d want tour 8 venue 339", args, got.Data.TourID, got.Data.VenueID)
+		}
+	}
+}
+
+func TestSearchNoTracks(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/mixed_search.json")
+		}))
+	defer ts.Close()
+	search := func(args ...string) string {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs(append([]string{"search", "-s", "bowie"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "search"); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if got := search(); !strings.Contains(got, "TRACK RESULTS") {
+		t.Fatalf("wanted track results without --no-tracks in\n%s", got)
+	}
+	got := search("--no-tracks")
+	if strings.Contains(got, "TRACK RESULTS") || strings.Contains(got, "Stash") {
+		t.Errorf("wanted no track results in\n%s", got)
+	}
+	for _, section := range []string{"SONG RESULTS", "David Bowie", "VENUE RESULTS", "The Academy"} {
+		if !strings.Contains(got, section) {
+			t.Errorf("wanted %s in\n%s", section, got)
+		}
+	}
+	if got := search("--no-tracks", "--compact"); got != "1 song, 1 venue\n" {
+		t.Errorf("got compact %q", got)
+	}
+}
+
+func TestSearchContext(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/boulder_search.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	if err := c.fromArgs([]string{"search", "-s", "boulder", "--context", "10"}); err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "search"); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	// the notes mention boulders, the transcript doesn't mention it at all
+	if want := "Notes:\n...rocks and boulders, their c...\n"; !strings.Contains(got, want) {
+		t.Errorf("wanted %q in\n%s", want, got)
+	}
+	if want := "Transcript:\nTREY: Okay, outside...\n"; !strings.Contains(got, want) {
+		t.Errorf("wanted %q in\n%s", want, got)
+	}
+
+	if err := NewClient("dummy", io.Discard).fromArgs([]string{"shows", "--context", "10"}); err == nil {
+		t.Error("wanted an error for context outside search")
+	}
+}
+
+func TestCompactSearch(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/boulder_search.json")
+		}))
+	defer ts.Close()
+	t.Run("text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs([]string{"search", "-s", "boulder", "--compact"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "search"); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := buf.String(), "1 track tag, 1 venue\n"; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewClient("dummy", buf)
+		if err := c.fromArgs([]string{"search", "-s", "boulder", "--compact", "-o", "json"}); err != nil {
+			t.Fatal(err)
+		}
+		c.BaseURL = ts.URL
+		c.HTTPClient = ts.Client()
+		if err := c.run(context.Background(), "search"); err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Data SearchCountsOutput `json:"data"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		want := []SectionCount{{Section: "track_tag", Count: 1}, {Section: "venue", Count: 1}}
+		if !reflect.DeepEqual(got.Data.Counts, want) {
+			t.Errorf("got %+v want %+v", got.Data.Counts, want)
+		}
+	})
+	t.Run("counts each result", func(t *testing.T) {
+		var s SearchOutput
+		s.Results.Tracks = make([]TrackOutput, 3)
+		s.Results.Songs = make([]SongOutput, 2)
+		var sb strings.Builder
+		if err := s.counts().PrettyPrint(&sb, false); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := sb.String(), "2 songs, 3 tracks\n"; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}
+
+func TestFutureEras(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../testdata/future_eras.json")
+		}))
+	defer ts.Close()
+	buf := &bytes.Buffer{}
+	c := NewClient("dummy", buf)
+	c.BaseURL = ts.URL
+	c.HTTPClient = ts.Client()
+	if err := c.run(context.Background(), "eras"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Eras\n1.0: 1983-1987, 1988\n2.0: 2002, 2003, 2004\n3.0: 2009, 2010\n4.0: 2021, 2022, 2023\n5.0: 2031, 2032\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s want\n%s", got, want)
+	}
+	names := ErasOutput{"10.0": nil, "5.0": nil, "1.0": nil}.names()
+	if !reflect.DeepEqual(names, []string{"1.0", "5.0", "10.0"}) {
+		t.Errorf("got %v want eras in numeric order", names)
+	}
+}
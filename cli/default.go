@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// DefaultClient is the client used by the package-level helpers (GetShows,
+// GetShow, etc.). Change its BaseURL or HTTPClient to point the helpers
+// elsewhere.
+var DefaultClient = NewClient("", io.Discard)
+
+// withKey returns a copy of DefaultClient using apiKey, so callers with
+// different keys don't interfere with each other.
+func withKey(apiKey string) *Client {
+	c := *DefaultClient
+	c.APIKey = apiKey
+	return &c
+}
+
+// entityURL is the url for a single entity, e.g. /shows/1995-12-31.
+func (c *Client) entityURL(path, id string) string {
+	return fmt.Sprintf("%s/%s/%s", c.BaseURL, path, id)
+}
+
+// GetEras lists the eras and their years with DefaultClient.
+func GetEras(ctx context.Context, apiKey string) (ErasOutput, error) {
+	c := withKey(apiKey)
+	return c.getEras(ctx, c.FormatURL(erasPath))
+}
+
+// GetYears lists the years and their show counts with DefaultClient.
+func GetYears(ctx context.Context, apiKey string) (YearsOutput, error) {
+	c := withKey(apiKey)
+	return c.getYears(ctx, c.FormatURL(yearsPath))
+}
+
+// GetShows lists the first page of shows with DefaultClient.
+func GetShows(ctx context.Context, apiKey string) (ShowsOutput, error) {
+	c := withKey(apiKey)
+	return c.getShows(ctx, c.FormatURL(showsPath))
+}
+
+// GetShow gets a show by date (yyyy-mm-dd) or id with DefaultClient.
+func GetShow(ctx context.Context, apiKey, dateOrID string) (ShowOutput, error) {
+	c := withKey(apiKey)
+	return c.getShow(ctx, c.entityURL(showsPath, dateOrID))
+}
+
+// GetSongs lists the first page of songs with DefaultClient.
+func GetSongs(ctx context.Context, apiKey string) (SongsOutput, error) {
+	c := withKey(apiKey)
+	return c.getSongs(ctx, c.FormatURL(songsPath))
+}
+
+// GetSong gets a song by slug or id with DefaultClient.
+func GetSong(ctx context.Context, apiKey, slugOrID string) (SongOutput, error) {
+	c := withKey(apiKey)
+	return c.getSong(ctx, c.entityURL(songsPath, slugOrID))
+}
+
+// GetTours lists the tours with DefaultClient.
+func GetTours(ctx context.Context, apiKey string) (ToursOutput, error) {
+	c := withKey(apiKey)
+	return c.getTours(ctx, c.FormatURL(toursPath))
+}
+
+// GetVenues lists the first page of venues with DefaultClient.
+func GetVenues(ctx context.Context, apiKey string) (VenuesOutput, error) {
+	c := withKey(apiKey)
+	return c.getVenues(ctx, c.FormatURL(venuesPath))
+}
+
+// GetTrack gets a track by id with DefaultClient.
+func GetTrack(ctx context.Context, apiKey, id string) (TrackOutput, error) {
+	c := withKey(apiKey)
+	return c.getTrack(ctx, c.entityURL(tracksPath, id))
+}
+
+// GetSearch searches every entity for term with DefaultClient.
+func GetSearch(ctx context.Context, apiKey, term string) (SearchOutput, error) {
+	c := withKey(apiKey)
+	return c.getSearch(ctx, c.entityURL(searchPath, term))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"time"
 )
 
 const usage = `usage: phishin <endpoint argument> [<flags>]
@@ -24,47 +25,205 @@ outputs the following:
 
 getting started:
 	get an api key (info at https://phish.in/contact-info).
-	set it as an environment variable (PHISHIN_API_KEY).
+	set it as an environment variable (PHISHIN_API_KEY), or pass it with --api-key/--api-key-file.
 	go phishin!
 
 supported arguments:
 eras 			(-s as era, e.g. 3.0)
-years 			(-s as year, e.g. 1994)
+years 			(-s as year, e.g. 1994, or --from 1994 --to 2000 to list a range of years)
 songs 			(-s as song slug or song-id, e.g. harry-hood)
 tours 			(-s as tour slug or tour id, e.g. 1983-tour)
 venues 			(-s as venue slug or venue id, e.g. the-academy)
 shows 			(-s as show date or show id, e.g. 1994-10-31)
 show-on-date -s 	(query required, format as yyyy-mm-dd)
 shows-on-day-of-year -s (query required, format as 10-31)
-random-show
+random-show		(--year 1997 or --era 3.0 to constrain the pick, --seed to repeat it)
 tracks 			(-s as tracks id, e.g. 6693)
 search -s
 tags 			(-s as tag slug or tag id, e.g. sbd)
+verify <dir>		(check a show downloaded with --manifest, no api key needed)
+audit tours		(flag tours whose shows count doesn't match their shows)
+resolve songs <slug/id>	(print the id and slug of a song, tour, venue, or tag)
+report incomplete <year> (list shows flagged incomplete or without tracks, --all for every show)
+like shows <id>		(like a show or track, e.g. like tracks 6693, for the api key's account)
+auth-check		(check the api key is accepted before a long job)
+tour-diff <tour> --against <tour> (songs played on one tour but not the other, e.g.
+			tour-diff fall-tour-1997 --against fall-tour-1998)
 
 arguments correspond to the phishin endpoints, and one (and only one) argument must be specified.
 most allow an optional search query (-s/--search) to change the output from a list of
 entities to details about a particular entity. behavior can be customized further via flags.
 
 note: the two exceptions to the above are 'phishin help'/'phishin h' and 'phishin endpoint'/
-'phishin e'.
+'phishin e'. 'phishin help shows' (or 'phishin shows --help') prints the help for one endpoint.
+
+run several commands in one go with 'phishin --batch <file>', where each line of the file is
+a command (e.g. shows -pp 3). outputs are separated by a --- line.
 
 general flags:
 -s/--search		search query, format depends on the specific endpoint
---debug			print the url that is being sent to the phishin server
+--api-key		phishin api key, takes precedence over --api-key-file and PHISHIN_API_KEY
+--api-key-file		file containing the phishin api key, takes precedence over PHISHIN_API_KEY
+--no-api-key		send requests without an api key, for endpoints that allow anonymous access
+			(the server decides). can't be used with --api-key or --api-key-file
+--debug			log the url of each request to the phishin server (same as --log-level debug)
+--log-level		minimum level to log to stderr: debug, info, warn (the default), or error
+--trace			log request and response headers to stderr (the api key is redacted)
+--record		save each api response under a directory (e.g. shows-1995-12-31.json, or
+			shows_page=2.json with query params), handy for new test fixtures
+--offline		with --record <dir>, only read the responses saved there, never the network.
+			a response that wasn't saved is a "not cached" error
+--strict-json		fail a request when the response has a field the client doesn't model, e.g. to
+			catch api changes in ci. the default ignores unknown fields
+--retries		retry a request failing with a network error, 429, or 5xx up to n times
+			(default 0), backing off from half a second. each track download retries on its own
+--retry-budget		max retries across all of a command's requests, e.g. for --all (default
+			10, 0 for no cap)
+--retry-non-idempotent	let --retries resend writes like likes too, which may then land twice.
+			by default only GET and HEAD requests are retried
+--metrics-file		after the run, write request, error, and downloaded byte counts, and the
+			run's duration, to a file in prometheus text format (e.g. metrics.prom).
+			not in a --batch
+--base-url		send requests to a mirror instead of https://phish.in/api/v1
+--insecure		skip tls verification, e.g. for a mirror with a self-signed cert
+			(requires --base-url)
+--proxy			send requests through a proxy (http, https, or socks5 url). otherwise
+			HTTP_PROXY/HTTPS_PROXY are honored
+-d			download the mp3s for a show, song, or track query. a song's tracks are
+			saved to <slug>/<date>.mp3, e.g. songs -s tweezer -d
+--output-dir		with -d, save downloads under a directory instead of the current one
+--by-year		with -d, save shows (and tracks) to <year>/<date>, e.g. 1997/1997-11-22/1-ghost.mp3
+--set			for a show, only include (and with -d, download) tracks from a set,
+			e.g. "Set 2" or encore
+--manifest		with -d, write a manifest.json describing a downloaded show
+--download-summary	with -d, skip each file's progress and print one line to stderr once the
+			downloads finish, e.g. "downloaded 23 files (312.4 MiB) in 41.2s, 0 failed"
+--skip-existing		with -d, skip files that are already downloaded in full, e.g. to finish an
+			interrupted download. a file is complete when it matches the show's manifest,
+			or the size phish.in reports for it (a HEAD request)
+--cover			for a show, download its cover art (when phish.in has any) to <date>/cover.jpg.
+			-v lists the cover art url, or the show's era when there's none
+--limit-rate		with -d, cap total download speed in bytes per second (e.g. 500k, 2m)
+--all			for report incomplete, scan every show instead of one year. for songs
+			--breakdown, count every song instead of one page
+--parallel		max number of concurrent requests for downloads and bulk fetches (default is 4)
 
 list-related flags:
--d/--sort-dir		direction to sort in. options are asc or desc
+-dir/--sort-dir		direction to sort in. options are asc or desc. without it, sorting by a date
+			or count is desc and by a name is asc
 -a/--sort-attr		attribute to sort on (e.g. name, date)
--pp/--per-page		number of results to list per page (default is 20)
+-pp/--per-page		number of results to list per page (default is 20). search and songs -s
+			take it (and -p) too
 -p/--page		which page of results to display (default is 1)
+--first/--last		for shows, get just the earliest (or most recent) show. overrides the sort
+			and paging flags
+--watch			with shows --last, check for a new show every interval (e.g. 5m) and print
+			it when one appears, until interrupted (so not in a --batch)
+--sbd			only include soundboard recordings. sent as -t sbd for /shows and /tracks,
+			and filtered client-side elsewhere (e.g. years -s 1994)
 -t/--tag		filter results by a specific tag (applicable for /tracks and /shows)
+--track-page		for song details, list a page of the song's tracks instead of all of them,
+			with a footer saying which page it is. pages are 20 tracks unless
+			--track-per-page sets another size
+--song			for tracks, only list performances of a song (by slug), e.g. --song tweezer
+--with-show-context	for tracks, add each track's place in its set (e.g. Set 2 #3) and how many
+			tracks its show has. fetches each listed track's show (once per show)
+--exclude-tag		drop shows and tracks with a tag, e.g. audience. repeat it (or separate tags
+			with commas) to drop several
+--min-likes		only include shows and tracks with at least n likes, e.g. --min-likes 10
+--min-tracks		only include shows with at least n tracks, e.g. --min-tracks 5 to skip
+			fragments. shows listed without their tracks (e.g. with --head-only) are
+			always kept
+--param			add a query parameter the cli doesn't have a flag for, e.g. --param venue_id=5.
+			repeat it to add several. detail routes (with -s) don't send parameters,
+			except songs and search
+--group-by		for shows lists, group the shows under their venue with a count for each,
+			e.g. --group-by venue. venues with the most shows come first
+--mark-tours		for shows lists, add a line naming the tour wherever a new tour starts
+			(one more request per tour listed)
+--head-only		for shows lists, skip each show's tracks (less to decode and hold)
+--since			only include shows/tracks updated since a date (1995-12-31) or span (24h, 7d, 2w)
+
+note: --exclude-tag, --min-likes, --min-tracks, and --since filter client-side, so they only
+narrow down the page of results the api returns.
 
 note: list-related flags are supported for /shows, /songs, /tracks, and /venues. they will
 be ignored if you include them for other commands.
 
 output-related flags:
--o/--output		options are json or text, default to text
+-o/--output		options are json or text, default to text. search also takes jsonl, one json
+			object per result with the section it's from (e.g. "venue"). lists also take
+			tsv, tab-separated rows under a header for pasting into a spreadsheet (shows
+			use the -v columns, so --columns and --links apply)
+--json-indent		number of spaces (or tab) to indent json output with, default is 2
+--json-root		nest json output under a top-level key, e.g. --json-root phishin
+--json-numbers-as-strings
+			write ids (id, show_id, song_ids, etc.) as strings in json output, for
+			importers that round big numbers, e.g. "id": "696"
+--color			auto (the default, color when writing to a terminal), always, or never.
+			NO_COLOR turns off auto color. tags are tinted with their phish.in color
+			(without color, -v lists the hex instead)
+--theme			color theme for dates, venues, and durations: default, ocean, or sunset.
+			PHISHIN_THEME sets the default
 -v/--verbose 		include extra information in output (not supported in all routes)
+--columns		with -v, pick and order the shows table columns (id, date, venue, location,
+			duration, sbd, remastered, link), e.g. date,venue,id
+--pager			page text output through $PAGER (less -FRX by default) when writing to a
+			terminal (not in a --batch). json output is never paged
+--highlight		mark each match of a term (ignoring case) in text output, in reverse video
+			when color is on or as **term** otherwise, e.g. --highlight bowie
+--include-empty		print a section's header even when it's empty, e.g. Show Dates: (none)
+--ascii			transliterate output to ascii (e.g. Montréal to Montreal) for terminals
+			without utf-8
+--duration-format	human (the default, e.g. 13m 31s), seconds, or clock, for show and track
+			durations. clock (H:MM:SS, e.g. 0:13:31 and 2:40:05) lines up in tables
+--max-tracks		print at most n of a show's or song's tracks, with a note saying how many
+			there are, e.g. songs -s david-bowie --max-tracks 10. json output has them all
+--merge-sets		list a show's tracks as one numbered list without set headers (-v adds
+			each track's set)
+--sort-tracks		order a show's tracks by duration or title (add :asc or :desc), listing them
+			as with --merge-sets, e.g. --sort-tracks duration:desc for the longest jam.
+			without a direction, durations are longest first
+--segues		join segued tracks with > in a show's setlist. phish.in has no segue data,
+			so a track counts as segued with a "Segue" tag, or a tag note mentioning a
+			segue (or "->")
+--next/--prev		for shows -s <date>, get the first show after (or before) that date instead,
+			e.g. shows -s 1994-10-31 --next. there's no api for it, so this walks the
+			years list from that date's year, fetching a year's shows at a time
+--setlist-notation	print a single show's setlist the way fans share it, one line per set,
+			e.g. "Set 1: Possum, Ya Mar > Bowie" (segues found as with --segues)
+--links			include each show's phish.in page (e.g. https://phish.in/1997-11-22)
+--prefetch-venue	for a single show, fetch the full venue (other names, coordinates, show dates)
+			instead of the partial venue the show carries
+--count			print only the total number of results for a list, e.g. shows -t sbd --count.
+			it's the api's total, so not with the client-side filters
+--share			print the phish.in page for a show, track, song, venue, or tour rather than
+			its details, e.g. shows -s 1997-11-22 --share
+--summary		print a one-line summary above list output, e.g. "20 shows (page 1 of 88, 1760 total)"
+--nested-sets		group a show's tracks by set in json output
+--compact		for search, print just how many results each section has, e.g.
+			"3 tracks, 1 venue, 2 songs"
+--context		for search, cut track tag notes and transcripts down to n characters either
+			side of the search term, e.g. --context 40
+--no-tracks		for search, leave out the track results (in every output), keeping the rest
+--flatten		for search, list results as one array tagged by type in json output
+--expand		for tags -s, fetch and list every show the tag appears in. for tours -s -v,
+			fetch each show and list its setlist
+--breakdown		for songs, count Phish originals and covers, and list the 10 most covered
+			artists. counts the listed page, or every song with --all
+--by-artist		for songs, group the listed songs by original artist (-v to list titles)
+--counts		for eras, print each era's years and total shows, summed from the years
+			list (one more request)
+--normalize-dates	for eras and years, list each year of the 1983-1987 span on its own. phish.in
+			only counts shows for the whole span, so years fetches the span's shows (one
+			more request) and counts them by date. years with no shows list 0
+--dedupe		for venues, collapse a venue listed under more than one name: venues at the
+			same location where one's name is another's name or past name. their show
+			dates and counts are merged under the first one listed
+--by-city		for venues, total the listed venues and their shows by city (also
+			--by-state and --by-country)
+--songs			for venues -s, fetch the venue's shows and list the songs played there, most
+			played first. --limit n only fetches the first n shows
 
 get a blank space where results should be? try the following:
 format dates as "1995-12-31"
@@ -74,6 +233,168 @@ enter all or part of song names, tour names, etc (like "summer", "1995", "sbd",
 see https://phish.in/api-docs for more details
 `
 
+// endpointHelp is the help for each endpoint (phishin help shows, or
+// phishin shows --help): its query, the flags that apply, and examples.
+var endpointHelp = map[string]string{
+	erasPath: `usage: phishin eras [-s <era>] [<flags>]
+	list the eras and their years, or with -s (e.g. 3.0), the years of one era.
+
+flags:
+--normalize-dates	list each year of the 1983-1987 span on its own
+--counts		total each era's shows from the years list (one more request)
+
+examples:
+	phishin eras
+	phishin eras --counts
+	phishin eras -s 1.0 --normalize-dates
+`,
+	yearsPath: `usage: phishin years [-s <year>] [<flags>]
+	list the years and their show counts, or with -s (e.g. 1994 or 1983-1987), the
+	shows of a year.
+
+flags:
+--from/--to		only list years in a range
+--normalize-dates	list each year of the 1983-1987 span on its own
+--sbd			for a year's shows, only include soundboard recordings
+
+examples:
+	phishin years --from 1994 --to 2000
+	phishin years -s 1997
+`,
+	songsPath: `usage: phishin songs [-s <slug or id>] [<flags>]
+	list songs, or with -s (e.g. harry-hood), a song and each performance of it.
+
+flags:
+-pp/-p, --sort-dir/--sort-attr	page and sort the list
+--by-artist		group the listed songs by original artist
+--breakdown		count originals and covers (--all for every song, not just a page)
+--track-page		list a page of a song's tracks (with --track-per-page for its size)
+-d			download each performance to <slug>/<date>.mp3
+
+examples:
+	phishin songs -a name -dir asc
+	phishin songs -s tweezer --track-page 2
+`,
+	toursPath: `usage: phishin tours [-s <slug or id>] [<flags>]
+	list tours, or with -s (e.g. 1983-tour), a tour and its shows.
+
+flags:
+-v --expand		fetch each of a tour's shows and list its setlist
+--share			print the tour's phish.in page
+
+examples:
+	phishin tours
+	phishin tours -s fall-tour-1997 -v --expand
+`,
+	venuesPath: `usage: phishin venues [-s <slug or id>] [<flags>]
+	list venues, or with -s (e.g. the-academy), a venue and its show dates.
+
+flags:
+-pp/-p, --sort-dir/--sort-attr	page and sort the list
+--dedupe		collapse a venue listed under more than one name
+--by-city		total the listed venues by city (also --by-state and --by-country)
+--songs			with -s, list the songs played across the venue's shows (--limit n to
+			only fetch the first n shows)
+
+examples:
+	phishin venues --by-state
+	phishin venues -s madison-square-garden
+	phishin venues -s the-academy --songs
+`,
+	showsPath: `usage: phishin shows [-s <date or id>] [<flags>]
+	list shows, or with -s (e.g. 1994-10-31), a show and its setlist.
+
+flags:
+-pp/-p, --sort-dir/--sort-attr	page and sort the list
+--first/--last		get just the earliest (or most recent) show
+--next/--prev		with -s <date>, get the show after (or before) that date
+--watch			with --last, print each new show as it appears, e.g. --watch 5m
+-t/--tag		only list shows with a tag, e.g. -t sbd (--sbd for short)
+--exclude-tag		drop shows with a tag
+--mark-tours		name each tour where it starts in the list
+--group-by venue	group the list under each venue
+--set			for a show, only include tracks from a set, e.g. "Set 2"
+--sort-tracks		order a show's tracks by duration or title
+-d			download a show's mp3s to <date>/
+
+examples:
+	phishin shows -t sbd -pp 5
+	phishin shows -s 1997-11-22 -v
+	phishin shows -s 1995-12-31 --sort-tracks duration:desc
+`,
+	showOnDatePath: `usage: phishin show-on-date -s <yyyy-mm-dd> [<flags>]
+	get the show played on a date. -s is required.
+
+examples:
+	phishin show-on-date -s 1995-12-31
+`,
+	showsDayOfYearPath: `usage: phishin shows-on-day-of-year -s <mm-dd> [<flags>]
+	list the shows played on a day of any year. -s is required.
+
+examples:
+	phishin shows-on-day-of-year -s 10-31
+`,
+	randomShowPath: `usage: phishin random-show [<flags>]
+	get a random show.
+
+flags:
+--year/--era		only pick from a year (e.g. 1997) or an era (e.g. 3.0)
+--seed			repeat a pick
+
+examples:
+	phishin random-show --era 1.0
+`,
+	tracksPath: `usage: phishin tracks [-s <id>] [<flags>]
+	list tracks, or with -s (e.g. 6693), a track.
+
+flags:
+-pp/-p, --sort-dir/--sort-attr	page and sort the list
+-t/--tag		only list tracks with a tag, e.g. -t jamcharts
+--song			only list performances of a song (by slug)
+--with-show-context	add each track's place in its set and its show's track count
+-d			download a track's mp3
+
+examples:
+	phishin tracks --song harry-hood -pp 5
+	phishin tracks -s 6693 -v
+`,
+	searchPath: `usage: phishin search -s <term> [<flags>]
+	search shows, songs, tags, tours, tracks, and venues. -s is required.
+
+flags:
+-o jsonl		print one json object per result
+--compact		print just how many results each section has
+--context		cut track tag notes and transcripts to n characters around the term
+--no-tracks		leave out the track results
+--flatten		with -o json, list results as one array tagged by type
+
+examples:
+	phishin search -s bowie --no-tracks
+	phishin search -s gamehendge --context 40
+`,
+	tagsPath: `usage: phishin tags [-s <slug or id>] [<flags>]
+	list tags, or with -s (e.g. sbd), a tag and the shows and tracks it's on.
+
+flags:
+--expand		fetch and list every show the tag appears in
+
+examples:
+	phishin tags
+	phishin tags -s sbd --expand
+`,
+}
+
+// wantsHelp reports whether args (following the endpoint) ask for help.
+func wantsHelp(args []string) bool {
+	for _, a := range args {
+		switch a {
+		case "-h", "-help", "--help":
+			return true
+		}
+	}
+	return false
+}
+
 const searchTips = `
 get a blank space where results should be? try the following:
 format dates as "1995-12-31"
@@ -143,6 +464,24 @@ const (
 	tagsPath           = "tags"
 )
 
+// commands that don't correspond to a phishin endpoint
+const (
+	// checks downloaded files against their manifest
+	verifyPath = "verify"
+	// data-quality checks, e.g. audit tours
+	auditPath = "audit"
+	// catalog reports, e.g. report incomplete
+	reportPath = "report"
+	// looks up an entity's id from its slug, or vice versa
+	resolvePath = "resolve"
+	// likes a show or track for the api key's account (POST /likes)
+	likePath = "like"
+	// compares the songs two tours played
+	tourDiffPath = "tour-diff"
+	// checks the api key with one cheap request
+	authCheckPath = "auth-check"
+)
+
 func Run(args []string) int {
 	if len(args) < 1 {
 		fmt.Fprint(os.Stderr, usage)
@@ -150,37 +489,109 @@ func Run(args []string) int {
 	}
 	switch strings.ToLower(args[0]) {
 	case "help", "h", "-help", "-h", "--help":
+		if len(args) > 1 {
+			return runHelp(args[1])
+		}
 		fmt.Fprint(os.Stderr, usage)
 		return 0
 	case "endpoints", "e", "-endpoints", "-e", "--endpoints":
 		fmt.Fprintln(os.Stderr, endpointList)
 		return 0
+	case verifyPath:
+		return runVerify(args[1:])
+	case "--batch", "-batch":
+		return runBatch(args[1:])
 	}
-	apiKey := os.Getenv("PHISHIN_API_KEY")
-	if apiKey == "" {
-		fmt.Fprintln(os.Stderr, "please set the PHISHIN_API_KEY environment variable and try again")
-		fmt.Fprintln(os.Stderr, "keys may be requested via https://phish.in/contact-info")
-		return 1
+	if _, ok := endpointHelp[strings.ToLower(args[0])]; ok && wantsHelp(args[1:]) {
+		return runHelp(strings.ToLower(args[0]))
 	}
-	c := NewClient(apiKey, os.Stdout)
+	c := NewClient(os.Getenv("PHISHIN_API_KEY"), os.Stdout)
 
 	if err := c.fromArgs(args); err != nil {
 		fmt.Fprintln(os.Stderr, fmt.Errorf("unable to parse args: %w", err))
 		return 1
 	}
+	if c.APIKey == "" && !c.NoAPIKey {
+		fmt.Fprintln(os.Stderr, "please set the PHISHIN_API_KEY environment variable (or use --api-key) and try again")
+		fmt.Fprintln(os.Stderr, "or try without one (--no-api-key), for endpoints that allow it")
+		fmt.Fprintln(os.Stderr, "keys may be requested via https://phish.in/contact-info")
+		return 1
+	}
+
+	closePager, err := c.startPager()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer closePager()
+
+	if c.MetricsFile != "" {
+		start := time.Now()
+		defer func() {
+			if err := c.writeMetricsFile(time.Since(start)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
 
-	// get context at this point?
-	// customize? or not...
-	c.ErrGroup.SetLimit(4)
+	c.ErrGroup.SetLimit(c.Parallel)
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
 	path := args[0]
+	start := time.Now()
 	if err := c.run(ctx, path); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
-	if err := c.ErrGroup.Wait(); err != nil {
+	if err := c.waitForDownloads(start); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runHelp prints the help for an endpoint.
+func runHelp(endpoint string) int {
+	help, ok := endpointHelp[strings.ToLower(endpoint)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "no help for %s\n", endpoint)
+		fmt.Fprintln(os.Stderr, endpointList)
+		return 1
+	}
+	fmt.Fprint(os.Stderr, help)
+	return 0
+}
+
+func runVerify(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: phishin verify <dir>")
+		return 1
+	}
+	o, err := verifyManifest(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := o.PrettyPrint(os.Stdout, false); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.Failures() != 0 {
+		return 1
+	}
+	return 0
+}
+
+func runBatch(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: phishin --batch <file>")
+		return 1
+	}
+	c := NewClient(os.Getenv("PHISHIN_API_KEY"), os.Stdout)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+	if err := c.runBatch(ctx, args[0]); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
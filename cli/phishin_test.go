@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -18,3 +19,20 @@ func TestRun(t *testing.T) {
 		}
 	})
 }
+
+func TestEndpointHelp(t *testing.T) {
+	if !strings.Contains(endpointHelp[showsPath], "-t/--tag") {
+		t.Errorf("wanted the tag flag in the shows help:\n%s", endpointHelp[showsPath])
+	}
+	if strings.Contains(endpointHelp[erasPath], "--tag") {
+		t.Error("didn't want the tag flag in the eras help")
+	}
+	for _, args := range [][]string{{"help", "shows"}, {"shows", "--help"}, {"shows", "-s", "1997-11-22", "-h"}, {"Shows", "--help"}} {
+		if i := Run(args); i != 0 {
+			t.Errorf("%v: got %d want 0", args, i)
+		}
+	}
+	if i := Run([]string{"help", "setlists"}); i != 1 {
+		t.Errorf("got %d want 1 for an unknown endpoint", i)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/davemolk/phishin
 
-go 1.20
+go 1.21
 
 require golang.org/x/sync v0.6.0